@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/arvancloud/cli/pkg/cli"
+	"github.com/arvancloud/cli/pkg/options"
 )
 
 func main() {
@@ -16,8 +17,6 @@ func main() {
 		runtime.GOMAXPROCS(runtime.NumCPU())
 	}
 
-	command := cli.NewCommandCLI()
-	if err := command.Execute(); err != nil {
-		os.Exit(1)
-	}
+	o := options.NewDefaultOptions()
+	cli.Execute(cli.NewCommandCLI(o), o)
 }
@@ -3,12 +3,12 @@ package api
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 
 	"github.com/arvancloud/cli/pkg/config"
+	"github.com/arvancloud/cli/pkg/utl"
 	"k8s.io/client-go/rest"
 )
 
@@ -21,32 +21,52 @@ const (
 	updateServer    = "https://cli.arvanpaas.ir"
 )
 
+// Client sends requests to arvan api server using credentials of its ConfigInfo.
+type Client struct {
+	// Config holds server url and authorization info
+	Config *config.ConfigInfo
+
+	// HTTPClient sends the requests
+	HTTPClient *http.Client
+
+	// UpdateServer is base url of server serving cli updates
+	UpdateServer string
+}
+
+// NewClient returns a Client using http.DefaultClient to access api server of arvanConfig.
+func NewClient(arvanConfig *config.ConfigInfo) *Client {
+	return &Client{
+		Config:       arvanConfig,
+		HTTPClient:   http.DefaultClient,
+		UpdateServer: updateServer,
+	}
+}
+
 // GetUserInfo returns a dictionary of user info if authentication credentials is valid.
-func GetUserInfo(apikey string) (map[string]string, error) {
-	arvanConfig := config.GetConfigInfo()
-	arvanServer := arvanConfig.GetServer()
+func (c *Client) GetUserInfo(apikey string) (map[string]string, error) {
+	arvanServer := c.Config.GetServer()
 	httpReq, err := http.NewRequest("GET", arvanServer+userEndpoint, nil)
 	if err != nil {
 		return nil, err
 	}
 	httpReq.Header.Add("Authorization", apikey)
 	httpReq.Header.Add("User-Agent", rest.DefaultKubernetesUserAgent())
-	httpResp, err := http.DefaultClient.Do(httpReq)
+	httpResp, err := c.HTTPClient.Do(httpReq)
 	if err != nil {
-		return nil, err
+		return nil, utl.NewError(utl.KindNetwork, err)
 	}
 
 	// read body
 	defer httpResp.Body.Close()
 	body, err := ioutil.ReadAll(httpResp.Body)
 	if err != nil {
-		return nil, err
+		return nil, utl.NewError(utl.KindNetwork, err)
 	}
 	if httpResp.StatusCode != http.StatusOK {
 		if httpResp.StatusCode >= 400 && httpResp.StatusCode < 500 {
-			return nil, errors.New("invalid authorization credentials")
+			return nil, utl.NewError(utl.KindAuth, errors.New("invalid authorization credentials"))
 		} else {
-			return nil, errors.New("server error. try again later")
+			return nil, utl.NewError(utl.KindServer, errors.New("server error. try again later"))
 		}
 	}
 
@@ -61,12 +81,11 @@ func GetUserInfo(apikey string) (map[string]string, error) {
 }
 
 // GetZones from PaaS API
-func GetZones() (config.Region, error) {
+func (c *Client) GetZones() (config.Region, error) {
 	var regions config.Region
-	arvanConfig := config.GetConfigInfo()
-	arvanURL, err := url.Parse(arvanConfig.GetServer())
+	arvanURL, err := url.Parse(c.Config.GetServer())
 	if err != nil {
-		return regions, fmt.Errorf("invalid config")
+		return regions, utl.Errorf(utl.KindValidation, "invalid config")
 	}
 
 	httpReq, err := http.NewRequest("GET", arvanURL.Scheme+"://"+arvanURL.Host+regionsEndpoint, nil)
@@ -74,27 +93,30 @@ func GetZones() (config.Region, error) {
 		return regions, err
 	}
 
-	apikey := arvanConfig.GetApiKey()
-	if apikey != "" {
-		httpReq.Header.Add("Authorization", apikey)
+	authorization := c.Config.GetAuthorization()
+	if authorization != "" {
+		httpReq.Header.Add("Authorization", authorization)
 	}
 
 	httpReq.Header.Add("accept", "application/json")
 	httpReq.Header.Add("User-Agent", rest.DefaultKubernetesUserAgent())
-	httpResp, err := http.DefaultClient.Do(httpReq)
+	httpResp, err := c.HTTPClient.Do(httpReq)
 	if err != nil {
-		return regions, err
+		return regions, utl.NewError(utl.KindNetwork, err)
 	}
 	// read body
 	defer httpResp.Body.Close()
 	body, err := ioutil.ReadAll(httpResp.Body)
 	if err != nil {
-		return regions, err
+		return regions, utl.NewError(utl.KindNetwork, err)
+	}
+	if httpResp.StatusCode >= 500 {
+		return regions, utl.NewError(utl.KindServer, errors.New("server error. try again later"))
 	}
 	// parse response
 	err = json.Unmarshal(body, &regions)
 	if err != nil {
-		return regions, err
+		return regions, utl.NewError(utl.KindServer, err)
 	}
 
 	return regions, nil
@@ -105,16 +127,17 @@ type Update struct {
 	Version string
 }
 
-func CheckUpdate() (*Update, error) {
-	httpReq, err := http.NewRequest("GET", updateServer+updateEndpoint, nil)
+// CheckUpdate returns latest version of cli if an update is available.
+func (c *Client) CheckUpdate() (*Update, error) {
+	httpReq, err := http.NewRequest("GET", c.UpdateServer+updateEndpoint, nil)
 	if err != nil {
 		return nil, err
 	}
 	httpReq.Header.Add("accept", "application/json")
 	httpReq.Header.Add("User-Agent", rest.DefaultKubernetesUserAgent())
-	httpResp, err := http.DefaultClient.Do(httpReq)
+	httpResp, err := c.HTTPClient.Do(httpReq)
 	if err != nil {
-		return nil, err
+		return nil, utl.NewError(utl.KindNetwork, err)
 	}
 
 	if httpResp.StatusCode == http.StatusNoContent {
@@ -122,7 +145,7 @@ func CheckUpdate() (*Update, error) {
 	}
 
 	if httpResp.StatusCode != http.StatusOK {
-		return nil, errors.New("server error. try again later")
+		return nil, utl.NewError(utl.KindServer, errors.New("server error. try again later"))
 	}
 
 	// read body
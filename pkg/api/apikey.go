@@ -0,0 +1,140 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+const (
+	apiKeysEndpoint       = "/g/apikeys"
+	currentApiKeyEndpoint = "/g/apikeys/current"
+)
+
+// ApiKey of arvan account. Key is only returned when the key is created.
+type ApiKey struct {
+	ID          string     `json:"id"`
+	Description string     `json:"description"`
+	Key         string     `json:"key,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+}
+
+type apiKeysResponse struct {
+	ApiKeys []ApiKey `json:"data"`
+}
+
+type apiKeyResponse struct {
+	ApiKey ApiKey `json:"data"`
+}
+
+// ListApiKeys returns api keys of current account.
+func (c *Client) ListApiKeys() ([]ApiKey, error) {
+	var response apiKeysResponse
+	err := c.accountRequest(http.MethodGet, apiKeysEndpoint, c.Config.GetAuthorization(), nil, &response)
+	if err != nil {
+		return nil, err
+	}
+	return response.ApiKeys, nil
+}
+
+// CreateApiKey creates a new api key for current account.
+func (c *Client) CreateApiKey(description string) (*ApiKey, error) {
+	var response apiKeyResponse
+	payload := map[string]string{"description": description}
+	err := c.accountRequest(http.MethodPost, apiKeysEndpoint, c.Config.GetAuthorization(), payload, &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response.ApiKey, nil
+}
+
+// RevokeApiKey revokes api key with the given id.
+func (c *Client) RevokeApiKey(id string) error {
+	return c.accountRequest(http.MethodDelete, apiKeysEndpoint+"/"+url.PathEscape(id), c.Config.GetAuthorization(), nil, nil)
+}
+
+// RevokeCurrentApiKey revokes apikey itself, authorizing the request using it.
+func (c *Client) RevokeCurrentApiKey(apikey string) error {
+	return c.accountRequest(http.MethodDelete, currentApiKeyEndpoint, apikey, nil, nil)
+}
+
+// accountRequest sends a request to account api of arvan api server and parses its response into result if it's not nil.
+func (c *Client) accountRequest(method, endpoint, apikey string, payload, result interface{}) error {
+	arvanURL, err := url.Parse(c.Config.GetServer())
+	if err != nil {
+		return utl.Errorf(utl.KindValidation, "invalid config")
+	}
+
+	var requestBody io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		requestBody = bytes.NewBuffer(data)
+	}
+
+	httpReq, err := http.NewRequest(method, arvanURL.Scheme+"://"+arvanURL.Host+endpoint, requestBody)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Add("Authorization", apikey)
+	httpReq.Header.Add("accept", "application/json")
+	if payload != nil {
+		httpReq.Header.Add("Content-Type", "application/json")
+	}
+	httpReq.Header.Add("User-Agent", rest.DefaultKubernetesUserAgent())
+	httpResp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return utl.NewError(utl.KindNetwork, err)
+	}
+
+	// read body
+	defer httpResp.Body.Close()
+	body, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return utl.NewError(utl.KindNetwork, err)
+	}
+
+	switch {
+	case httpResp.StatusCode == http.StatusUnauthorized || httpResp.StatusCode == http.StatusForbidden:
+		return utl.NewError(utl.KindAuth, errors.New("invalid authorization credentials"))
+	case httpResp.StatusCode == http.StatusNotFound:
+		return utl.NewError(utl.KindNotFound, errors.New(responseMessage(body, "api key not found")))
+	case httpResp.StatusCode >= 400 && httpResp.StatusCode < 500:
+		return utl.NewError(utl.KindValidation, errors.New(responseMessage(body, httpResp.Status)))
+	case httpResp.StatusCode >= 500:
+		return utl.NewError(utl.KindServer, errors.New("server error. try again later"))
+	}
+
+	if result == nil || len(body) == 0 {
+		return nil
+	}
+	// parse response
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return utl.NewError(utl.KindServer, err)
+	}
+	return nil
+}
+
+// responseMessage returns message of an error response body, or defaultMessage if it has none.
+func responseMessage(body []byte, defaultMessage string) string {
+	var response struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil || len(response.Message) == 0 {
+		return defaultMessage
+	}
+	return response.Message
+}
@@ -0,0 +1,546 @@
+// Package apitest provides an in-process fake of arvan api server for testing arvan cli commands.
+package apitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/arvancloud/cli/pkg/api"
+	"github.com/arvancloud/cli/pkg/config"
+	"github.com/arvancloud/cli/pkg/options"
+	"github.com/arvancloud/cli/pkg/paas"
+)
+
+const (
+	// DefaultApiKey is the api key accepted by a new Server
+	DefaultApiKey = "Apikey 00000000-0000-0000-0000-000000000000"
+
+	// DefaultUsername is the openshift user name returned by a new Server
+	DefaultUsername = "jane"
+
+	userPath        = "/g/user"
+	apiKeysPath     = "/g/apikeys"
+	currentApiKey   = "current"
+	zonesPath       = "/paas/v1/zones"
+	updatePath      = "/update"
+	regionsPrefix   = "/paas/v1/regions/"
+	migrateSuffix   = "/migrate"
+	whoAmISuffix    = "/o/apis/user.openshift.io/v1/users/~"
+	projectSuffix   = "/o/apis/project.openshift.io/v1/projects"
+	requestSuffix   = "/o/apis/project.openshift.io/v1/projectrequests"
+	paasInfix       = "/o/"
+	namespacesInfix = "/o/api/v1/namespaces/"
+	quotasSuffix    = "/resourcequotas"
+	deviceCodePath  = "/oauth/device/code"
+	tokenPath       = "/oauth/token"
+
+	// DeviceCode is the device code returned by /oauth/device/code
+	DeviceCode = "device-code"
+
+	// UserCode is the user code returned by /oauth/device/code
+	UserCode = "ABCD-EFGH"
+)
+
+// Server is a fake arvan api server. Its exported fields can be changed between requests to script its state;
+// lock it using Lock and Unlock while doing so if commands are running concurrently.
+type Server struct {
+	sync.Mutex
+
+	// Server serves the fake api over TLS
+	Server *httptest.Server
+
+	// ApiKey is the Authorization header value accepted in addition to keys of ApiKeys
+	ApiKey string
+
+	// ApiKeys are returned by /g/apikeys. Keys created using POST are accepted as well as ApiKey.
+	ApiKeys []api.ApiKey
+
+	// RejectApiKeys makes keys of ApiKeys not accepted, e.g to test failing validation of a new key
+	RejectApiKeys bool
+
+	// BearerToken is accepted as "Bearer <token>" and returned by /oauth/token once DeviceApproved is set
+	BearerToken string
+
+	// RefreshToken is returned along with BearerToken and exchanged for it
+	RefreshToken string
+
+	// TokenExpiresIn is lifetime of BearerToken in seconds returned by /oauth/token, zero if it never expires
+	TokenExpiresIn int
+
+	// DeviceApproved reports whether user approved DeviceCode. /oauth/token returns authorization_pending until then.
+	DeviceApproved bool
+
+	// ServiceAccountToken is accepted as "Bearer <token>" by paas api under /paas/v1/regions/
+	ServiceAccountToken string
+
+	// User is returned by /g/user
+	User map[string]string
+
+	// Username is the openshift user returned by users/~
+	Username string
+
+	// Zones are returned by /paas/v1/zones. Use AddZone to add zones served by this server.
+	Zones []config.Zone
+
+	// Projects are returned by projects, per zone name. Project requests add projects to it.
+	Projects map[string][]string
+
+	// Objects are returned as lists by paas api, per zone name and path of the list
+	// e.g "at1/api/v1/namespaces/shop/configmaps". POST to a list adds an object to it. Objects of lists
+	// are served by name too, supporting GET, PUT and DELETE.
+	Objects map[string][]map[string]interface{}
+
+	// Update is returned by /update. No update is available if it's nil.
+	Update *api.Update
+
+	// Migration scripts progress of migration, see Migration
+	Migration *Migration
+
+	requests []string
+}
+
+// Migration scripts responses of /paas/v1/{region}/migrate.
+// Each GET returns next response of Progress, repeating the last one when reached.
+type Migration struct {
+	// Requests holds requests sent using POST
+	Requests []paas.Request
+
+	// Progress is returned by subsequent GET requests after the migration is started.
+	Progress []paas.ProgressResponse
+
+	// Started reports whether GET requests return Progress. It's set on POST.
+	Started bool
+
+	// Message is returned along with POST response or 404 response of GET before the migration is started.
+	Message string
+
+	polls int
+}
+
+// NewServer starts a fake api server with a single UP zone named "at1" in region "ir-thr".
+// It should be closed using Close.
+func NewServer() *Server {
+	s := &Server{
+		ApiKey:   DefaultApiKey,
+		User:     map[string]string{"email": "jane@example.com", "id": "1"},
+		Username: DefaultUsername,
+		Projects: map[string][]string{},
+		Objects:  map[string][]map[string]interface{}{},
+	}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.serveHTTP))
+	s.AddZone("ir-thr", "at1", "UP")
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.Server.Close()
+}
+
+// URL returns base url of the server e.g https://127.0.0.1:34567
+func (s *Server) URL() string {
+	return s.Server.URL
+}
+
+// AddZone adds a zone served by this server with the given status e.g "UP" or "DOWN".
+// First zone added is the default zone.
+func (s *Server) AddZone(regionName, name, status string) config.Zone {
+	s.Lock()
+	defer s.Unlock()
+	s.Zones = append(s.Zones, config.Zone{
+		Name:          name,
+		Endpoint:      strings.TrimPrefix(s.Server.URL, "https://") + regionsPrefix + regionName + "-" + name,
+		Active:        status == "UP",
+		Status:        status,
+		Version:       "v1",
+		Release:       "STABLE",
+		Default:       len(s.Zones) == 0,
+		RegionName:    regionName,
+		RegionCity:    "Tehran",
+		RegionCountry: "Iran",
+	})
+	return s.Zones[len(s.Zones)-1]
+}
+
+// Requests returns method and path of requests received so far e.g "GET /g/user".
+func (s *Server) Requests() []string {
+	s.Lock()
+	defer s.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// NewConfigInfo returns a ConfigInfo logged in to first zone of the server using ApiKey, saving its files in homeDir.
+func (s *Server) NewConfigInfo(homeDir string) (*config.ConfigInfo, error) {
+	arvanConfig := config.NewConfigInfo(homeDir)
+	s.Lock()
+	arvanConfig.Initiate(s.ApiKey, s.Zones[0])
+	s.Unlock()
+	if _, err := arvanConfig.SaveConfig(); err != nil {
+		return nil, err
+	}
+	return arvanConfig, nil
+}
+
+// NewOptions returns Options using arvanConfig and a client trusting the server.
+func (s *Server) NewOptions(arvanConfig *config.ConfigInfo, in io.Reader, out, errout io.Writer) *options.Options {
+	o := options.NewOptions(arvanConfig, in, out, errout)
+	o.Client.HTTPClient = s.Server.Client()
+	o.Client.UpdateServer = s.Server.URL
+	return o
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+	path := r.URL.Path
+	switch {
+	case path == updatePath:
+		if s.Update == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, http.StatusOK, s.Update)
+	case path == zonesPath:
+		writeJSON(w, http.StatusOK, config.Region{Zones: s.Zones})
+	case path == deviceCodePath:
+		writeJSON(w, http.StatusOK, api.DeviceCode{
+			DeviceCode:      DeviceCode,
+			UserCode:        UserCode,
+			VerificationURI: s.Server.URL + "/device",
+			ExpiresIn:       600,
+			Interval:        1,
+		})
+	case path == tokenPath:
+		s.serveToken(w, r)
+	case !s.authorized(r):
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "Unauthenticated."})
+	case path == userPath:
+		writeJSON(w, http.StatusOK, s.User)
+	case path == apiKeysPath || strings.HasPrefix(path, apiKeysPath+"/"):
+		s.serveApiKeys(w, r)
+	case strings.HasPrefix(path, regionsPrefix) && strings.HasSuffix(path, whoAmISuffix):
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"kind":     "User",
+			"metadata": map[string]string{"name": s.Username},
+		})
+	case strings.HasPrefix(path, regionsPrefix) && strings.HasSuffix(path, projectSuffix):
+		zone := zoneName(strings.TrimSuffix(strings.TrimPrefix(path, regionsPrefix), projectSuffix))
+		items := make([]interface{}, 0)
+		for _, project := range s.Projects[zone] {
+			items = append(items, map[string]interface{}{
+				"metadata": map[string]string{"name": project},
+			})
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"kind": "ProjectList", "items": items})
+	case strings.HasPrefix(path, regionsPrefix) && strings.Contains(path, projectSuffix+"/"):
+		s.serveProject(w, r)
+	case strings.HasPrefix(path, regionsPrefix) && strings.HasSuffix(path, requestSuffix) && r.Method == http.MethodPost:
+		s.serveProjectRequest(w, r)
+	case strings.HasPrefix(path, regionsPrefix) && strings.Contains(path, namespacesInfix) && strings.HasSuffix(path, quotasSuffix):
+		writeJSON(w, http.StatusOK, map[string]interface{}{"kind": "ResourceQuotaList", "items": []interface{}{}})
+	case strings.HasPrefix(path, regionsPrefix) && s.servesObjects(path):
+		s.serveObjects(w, r)
+	case strings.HasPrefix(path, "/paas/v1/") && strings.HasSuffix(path, migrateSuffix):
+		s.serveMigration(w, r)
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "not found"})
+	}
+}
+
+// serveProject serves GET and DELETE of a single project of Projects.
+func (s *Server) serveProject(w http.ResponseWriter, r *http.Request) {
+	i := strings.Index(r.URL.Path, projectSuffix+"/")
+	zone := zoneName(strings.TrimPrefix(r.URL.Path[:i], regionsPrefix))
+	name := r.URL.Path[i+len(projectSuffix)+1:]
+
+	index := -1
+	for j, project := range s.Projects[zone] {
+		if project == name {
+			index = j
+		}
+	}
+	if index < 0 {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": fmt.Sprintf("projects %q not found", name)})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"kind":     "Project",
+			"metadata": map[string]string{"name": name},
+			"status":   map[string]string{"phase": "Active"},
+		})
+	case http.MethodDelete:
+		s.Projects[zone] = append(s.Projects[zone][:index:index], s.Projects[zone][index+1:]...)
+		writeJSON(w, http.StatusOK, map[string]string{"kind": "Status", "status": "Success"})
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"message": "method not allowed"})
+	}
+}
+
+// serveProjectRequest creates a project requested using POST, as "oc new-project" does.
+func (s *Server) serveProjectRequest(w http.ResponseWriter, r *http.Request) {
+	zone := zoneName(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, regionsPrefix), requestSuffix))
+
+	var request struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || len(request.Metadata.Name) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": "invalid project request"})
+		return
+	}
+	name := request.Metadata.Name
+	for _, project := range s.Projects[zone] {
+		if project == name {
+			writeJSON(w, http.StatusConflict, map[string]string{"message": fmt.Sprintf("project %q already exists", name)})
+			return
+		}
+	}
+
+	s.Projects[zone] = append(s.Projects[zone], name)
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"kind":       "Project",
+		"apiVersion": "project.openshift.io/v1",
+		"metadata":   map[string]string{"name": name},
+		"status":     map[string]string{"phase": "Active"},
+	})
+}
+
+func (s *Server) serveMigration(w http.ResponseWriter, r *http.Request) {
+	if s.Migration == nil {
+		s.Migration = &Migration{}
+	}
+	m := s.Migration
+
+	if r.Method == http.MethodPost {
+		body, err := ioutil.ReadAll(r.Body)
+		var request paas.Request
+		if err == nil {
+			err = json.Unmarshal(body, &request)
+		}
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": "invalid request"})
+			return
+		}
+		m.Requests = append(m.Requests, request)
+		m.Started = true
+		m.polls = 0
+		writeJSON(w, http.StatusOK, map[string]string{"message": m.Message})
+		return
+	}
+
+	if !m.Started || len(m.Progress) == 0 {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": m.Message})
+		return
+	}
+	i := m.polls
+	if i >= len(m.Progress) {
+		i = len(m.Progress) - 1
+	}
+	m.polls++
+	writeJSON(w, http.StatusOK, m.Progress[i])
+}
+
+func (s *Server) serveApiKeys(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, apiKeysPath), "/")
+
+	switch {
+	case r.Method == http.MethodGet && id == "":
+		apiKeys := make([]api.ApiKey, 0, len(s.ApiKeys))
+		for _, apiKey := range s.ApiKeys {
+			apiKey.Key = ""
+			apiKeys = append(apiKeys, apiKey)
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"data": apiKeys})
+	case r.Method == http.MethodPost && id == "":
+		var request struct {
+			Description string `json:"description"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		n := len(s.ApiKeys) + 1
+		apiKey := api.ApiKey{
+			ID:          fmt.Sprintf("key-%d", n),
+			Description: request.Description,
+			Key:         fmt.Sprintf("Apikey 00000000-0000-0000-0000-%012d", n),
+		}
+		s.ApiKeys = append(s.ApiKeys, apiKey)
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"data": apiKey})
+	case r.Method == http.MethodDelete && id == currentApiKey:
+		s.revokeApiKey(func(apiKey api.ApiKey) bool { return apiKey.Key == r.Header.Get("Authorization") })
+		if s.ApiKey == r.Header.Get("Authorization") {
+			s.ApiKey = ""
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodDelete:
+		if !s.revokeApiKey(func(apiKey api.ApiKey) bool { return apiKey.ID == id }) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "api key not found"})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"message": "method not allowed"})
+	}
+}
+
+// revokeApiKey removes api keys matching match and reports whether any was removed.
+func (s *Server) revokeApiKey(match func(api.ApiKey) bool) bool {
+	var apiKeys []api.ApiKey
+	for _, apiKey := range s.ApiKeys {
+		if !match(apiKey) {
+			apiKeys = append(apiKeys, apiKey)
+		}
+	}
+	revoked := len(apiKeys) != len(s.ApiKeys)
+	s.ApiKeys = apiKeys
+	return revoked
+}
+
+func (s *Server) serveToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	switch {
+	case r.PostForm.Get("grant_type") == "refresh_token" && len(s.RefreshToken) > 0 && r.PostForm.Get("refresh_token") == s.RefreshToken:
+	case r.PostForm.Get("device_code") != DeviceCode:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	case !s.DeviceApproved:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "authorization_pending"})
+		return
+	}
+	writeJSON(w, http.StatusOK, api.Token{
+		AccessToken:  s.BearerToken,
+		RefreshToken: s.RefreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    s.TokenExpiresIn,
+	})
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	authorization := r.Header.Get("Authorization")
+	if len(authorization) == 0 {
+		return false
+	}
+	if authorization == s.ApiKey {
+		return true
+	}
+	if len(s.BearerToken) > 0 && authorization == "Bearer "+s.BearerToken {
+		return true
+	}
+	if len(s.ServiceAccountToken) > 0 && authorization == "Bearer "+s.ServiceAccountToken {
+		return strings.HasPrefix(r.URL.Path, regionsPrefix)
+	}
+	for _, apiKey := range s.ApiKeys {
+		if apiKey.Key == authorization {
+			return !s.RejectApiKeys
+		}
+	}
+	return false
+}
+
+// zoneName returns zone name of a region e.g "at1" for "ir-thr-at1".
+func zoneName(region string) string {
+	return region[strings.LastIndex(region, "-")+1:]
+}
+
+// servesObjects reports whether path is a list of Objects or an object of one.
+func (s *Server) servesObjects(path string) bool {
+	key := objectsKey(path)
+	i := strings.LastIndex(key, "/")
+	return s.Objects[key] != nil || (i > 0 && s.Objects[key[:i]] != nil)
+}
+
+// serveObjects serves GET and POST of a list of Objects, or GET, PUT and DELETE of an object of a list by its name.
+func (s *Server) serveObjects(w http.ResponseWriter, r *http.Request) {
+	key := objectsKey(r.URL.Path)
+	if items, ok := s.Objects[key]; ok {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, map[string]interface{}{"kind": "List", "items": items})
+		case http.MethodPost:
+			s.createObject(w, r, key)
+		default:
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"message": "method not allowed"})
+		}
+		return
+	}
+
+	i := strings.LastIndex(key, "/")
+	listKey, name := key[:i], key[i+1:]
+	for j, item := range s.Objects[listKey] {
+		if metadata, _ := item["metadata"].(map[string]interface{}); metadata["name"] != name {
+			continue
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, item)
+		case http.MethodPut:
+			var obj map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&obj); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+				return
+			}
+			s.Objects[listKey][j] = obj
+			writeJSON(w, http.StatusOK, obj)
+		case http.MethodDelete:
+			s.Objects[listKey] = append(s.Objects[listKey][:j:j], s.Objects[listKey][j+1:]...)
+			writeJSON(w, http.StatusOK, map[string]string{"kind": "Status", "status": "Success"})
+		default:
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"message": "method not allowed"})
+		}
+		return
+	}
+	writeJSON(w, http.StatusNotFound, map[string]string{"message": fmt.Sprintf("%q not found", name)})
+}
+
+// createObject adds object posted by r to list of Objects keyed by key, unless an object of its name exists.
+func (s *Server) createObject(w http.ResponseWriter, r *http.Request, key string) {
+	var obj map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&obj); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	if len(name) == 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "name is required"})
+		return
+	}
+	for _, item := range s.Objects[key] {
+		if itemMetadata, _ := item["metadata"].(map[string]interface{}); itemMetadata["name"] == name {
+			writeJSON(w, http.StatusConflict, map[string]string{"message": fmt.Sprintf("%q already exists", name)})
+			return
+		}
+	}
+	s.Objects[key] = append(s.Objects[key], obj)
+	writeJSON(w, http.StatusCreated, obj)
+}
+
+// objectsKey returns key of Objects listed by path of paas api e.g "at1/api/v1/namespaces/shop/configmaps".
+func objectsKey(path string) string {
+	region := strings.TrimPrefix(path, regionsPrefix)
+	i := strings.Index(region, paasInfix)
+	if i < 0 {
+		return ""
+	}
+	return zoneName(region[:i]) + "/" + region[i+len(paasInfix):]
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Debug levels of http tracing.
+const (
+	// DebugOff disables http tracing.
+	DebugOff = iota
+	// DebugRequests logs method, url, status, latency and headers of every request.
+	DebugRequests
+	// DebugBodies additionally dumps request and response bodies.
+	DebugBodies
+)
+
+const (
+	// DebugEnv is the environment variable enabling http tracing, e.g. ARVAN_DEBUG=1 or ARVAN_DEBUG=2.
+	DebugEnv = "ARVAN_DEBUG"
+
+	redacted = "<redacted>"
+)
+
+// EnableDebug traces every request sent by c to out with the given level.
+// HTTPClient of c is replaced by a copy, so clients sharing the same http.Client are not affected.
+func (c *Client) EnableDebug(level int, out io.Writer) {
+	if level <= DebugOff {
+		return
+	}
+	if _, ok := c.HTTPClient.Transport.(*debugTransport); ok {
+		return
+	}
+	next := c.HTTPClient.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	httpClient := *c.HTTPClient
+	httpClient.Transport = &debugTransport{
+		level: level,
+		out:   out,
+		next:  next,
+	}
+	c.HTTPClient = &httpClient
+}
+
+// DebugLevelFromEnv returns debug level set using DebugEnv.
+// Any non-numeric value other than empty, "0" and "false" enables DebugRequests.
+func DebugLevelFromEnv() int {
+	value := strings.TrimSpace(os.Getenv(DebugEnv))
+	if value == "" || value == "false" {
+		return DebugOff
+	}
+	level, err := strconv.Atoi(value)
+	if err != nil {
+		return DebugRequests
+	}
+	return level
+}
+
+type debugTransport struct {
+	level int
+	out   io.Writer
+	next  http.RoundTripper
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fmt.Fprintf(t.out, "--> %s %s\n", req.Method, req.URL.String())
+	t.printHeaders("-->", req.Header)
+	if t.level >= DebugBodies && req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		t.printBody("-->", body)
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	latency := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		fmt.Fprintf(t.out, "<-- %s %s failed in %s: %v\n", req.Method, req.URL.String(), latency, err)
+		return resp, err
+	}
+
+	fmt.Fprintf(t.out, "<-- %s %s %s in %s\n", req.Method, req.URL.String(), resp.Status, latency)
+	t.printHeaders("<--", resp.Header)
+	if t.level >= DebugBodies && resp.Body != nil {
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+		t.printBody("<--", body)
+	}
+	return resp, nil
+}
+
+func (t *debugTransport) printHeaders(prefix string, header http.Header) {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range header[key] {
+			if strings.EqualFold(key, "Authorization") {
+				value = redactAuthorization(value)
+			}
+			fmt.Fprintf(t.out, "%s %s: %s\n", prefix, key, value)
+		}
+	}
+}
+
+func (t *debugTransport) printBody(prefix string, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	fmt.Fprintf(t.out, "%s %s\n", prefix, strings.TrimSpace(string(body)))
+}
+
+// redactAuthorization hides credentials of an authorization header value keeping its scheme, e.g. "Apikey <redacted>".
+func redactAuthorization(value string) string {
+	if i := strings.Index(value, " "); i > 0 {
+		return value[:i] + " " + redacted
+	}
+	return redacted
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/rest"
+
+	"github.com/arvancloud/cli/pkg/config"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+const (
+	deviceCodeEndpoint = "/oauth/device/code"
+	tokenEndpoint      = "/oauth/token"
+	oauthClientID      = "arvan-cli"
+	deviceCodeGrant    = "urn:ietf:params:oauth:grant-type:device_code"
+	refreshTokenGrant  = "refresh_token"
+
+	defaultPollInterval = 5 * time.Second
+	// tokenExpiryLeeway refreshes bearer tokens a bit before they expire so requests in flight don't fail
+	tokenExpiryLeeway = 30 * time.Second
+)
+
+// DeviceCode is returned by device authorization endpoint as described in RFC 8628.
+type DeviceCode struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval,omitempty"`
+}
+
+// Token is an oauth access token along with the refresh token renewing it.
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in,omitempty"`
+}
+
+type oauthError struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// Expiry returns time token expires at considering it's issued at now, zero if it never expires.
+func (t *Token) Expiry(now time.Time) time.Time {
+	if t.ExpiresIn <= 0 {
+		return time.Time{}
+	}
+	return now.Add(time.Duration(t.ExpiresIn) * time.Second)
+}
+
+// RequestDeviceCode starts device authorization of arvan cli. User should visit verification uri
+// of the returned code and enter its user code, while PollDeviceToken waits for them.
+func (c *Client) RequestDeviceCode() (*DeviceCode, error) {
+	var deviceCode DeviceCode
+	if _, err := c.oauthRequest(deviceCodeEndpoint, url.Values{"client_id": {oauthClientID}}, &deviceCode); err != nil {
+		return nil, err
+	}
+	return &deviceCode, nil
+}
+
+// PollDeviceToken polls token endpoint until user approves or denies deviceCode, or it expires.
+func (c *Client) PollDeviceToken(deviceCode *DeviceCode, clk clock.Clock) (*Token, error) {
+	interval := defaultPollInterval
+	if deviceCode.Interval > 0 {
+		interval = time.Duration(deviceCode.Interval) * time.Second
+	}
+	deadline := clk.Now().Add(time.Duration(deviceCode.ExpiresIn) * time.Second)
+
+	form := url.Values{
+		"client_id":   {oauthClientID},
+		"grant_type":  {deviceCodeGrant},
+		"device_code": {deviceCode.DeviceCode},
+	}
+	for {
+		var token Token
+		oauthErr, err := c.oauthRequest(tokenEndpoint, form, &token)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case oauthErr == nil:
+			return &token, nil
+		case oauthErr.Error == "slow_down":
+			interval += defaultPollInterval
+		case oauthErr.Error == "access_denied":
+			return nil, utl.NewError(utl.KindAuth, errors.New("login request denied"))
+		case oauthErr.Error == "expired_token":
+			return nil, utl.NewError(utl.KindAuth, errors.New("login request expired. try again"))
+		case oauthErr.Error != "authorization_pending":
+			return nil, utl.NewError(utl.KindAuth, errors.New(oauthErr.message()))
+		}
+
+		if deviceCode.ExpiresIn > 0 && !clk.Now().Before(deadline) {
+			return nil, utl.NewError(utl.KindAuth, errors.New("login request expired. try again"))
+		}
+		clk.Sleep(interval)
+	}
+}
+
+// RefreshToken returns a new access token using refreshToken.
+func (c *Client) RefreshToken(refreshToken string) (*Token, error) {
+	var token Token
+	oauthErr, err := c.oauthRequest(tokenEndpoint, url.Values{
+		"client_id":     {oauthClientID},
+		"grant_type":    {refreshTokenGrant},
+		"refresh_token": {refreshToken},
+	}, &token)
+	if err != nil {
+		return nil, err
+	}
+	if oauthErr != nil {
+		return nil, utl.NewError(utl.KindAuth, errors.New("session expired. \nTry \"arvan login --web\""))
+	}
+	return &token, nil
+}
+
+// EnsureFreshToken refreshes bearer token of config if it's expired at now and saves the config.
+// It does nothing for other types of credentials.
+func (c *Client) EnsureFreshToken(now time.Time) error {
+	expiry := c.Config.GetTokenExpiry()
+	if c.Config.GetAuthType() != config.AuthTypeBearer || expiry.IsZero() || now.Add(tokenExpiryLeeway).Before(expiry) {
+		return nil
+	}
+	if len(c.Config.GetRefreshToken()) == 0 {
+		return utl.NewError(utl.KindAuth, errors.New("session expired. \nTry \"arvan login --web\""))
+	}
+
+	token, err := c.RefreshToken(c.Config.GetRefreshToken())
+	if err != nil {
+		return err
+	}
+	refreshToken := token.RefreshToken
+	if len(refreshToken) == 0 {
+		refreshToken = c.Config.GetRefreshToken()
+	}
+	c.Config.SetBearerToken(token.AccessToken, refreshToken, token.Expiry(now))
+	_, err = c.Config.SaveConfig()
+	return err
+}
+
+// oauthRequest posts form to an oauth endpoint of arvan api server and parses its response into result.
+// Errors defined by oauth, e.g authorization_pending, are returned as oauthError rather than error.
+func (c *Client) oauthRequest(endpoint string, form url.Values, result interface{}) (*oauthError, error) {
+	arvanURL, err := url.Parse(c.Config.GetServer())
+	if err != nil {
+		return nil, utl.Errorf(utl.KindValidation, "invalid config")
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, arvanURL.Scheme+"://"+arvanURL.Host+endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Add("accept", "application/json")
+	httpReq.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Add("User-Agent", rest.DefaultKubernetesUserAgent())
+	httpResp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, utl.NewError(utl.KindNetwork, err)
+	}
+
+	// read body
+	defer httpResp.Body.Close()
+	body, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, utl.NewError(utl.KindNetwork, err)
+	}
+
+	switch {
+	case httpResp.StatusCode == http.StatusBadRequest || httpResp.StatusCode == http.StatusUnauthorized:
+		var oauthErr oauthError
+		if err = json.Unmarshal(body, &oauthErr); err != nil || len(oauthErr.Error) == 0 {
+			return nil, utl.NewError(utl.KindAuth, errors.New("invalid authorization request"))
+		}
+		return &oauthErr, nil
+	case httpResp.StatusCode == http.StatusNotFound:
+		return nil, utl.NewError(utl.KindNotFound, errors.New("web login is not supported by this server"))
+	case httpResp.StatusCode >= 400 && httpResp.StatusCode < 500:
+		return nil, utl.NewError(utl.KindValidation, errors.New(responseMessage(body, httpResp.Status)))
+	case httpResp.StatusCode >= 500:
+		return nil, utl.NewError(utl.KindServer, errors.New("server error. try again later"))
+	}
+
+	// parse response
+	if err = json.Unmarshal(body, result); err != nil {
+		return nil, utl.NewError(utl.KindServer, err)
+	}
+	return nil, nil
+}
+
+func (e *oauthError) message() string {
+	if len(e.Description) > 0 {
+		return e.Description
+	}
+	return e.Error
+}
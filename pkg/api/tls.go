@@ -0,0 +1,38 @@
+package api
+
+import (
+	"crypto/tls"
+	"net/http"
+)
+
+// InsecureSkipTLSVerify makes c skip verifying certificates of the servers it sends requests to.
+// HTTPClient of c is replaced by a copy, so clients sharing the same http.Client are not affected.
+func (c *Client) InsecureSkipTLSVerify() {
+	httpClient := *c.HTTPClient
+	httpClient.Transport = insecureTransport(httpClient.Transport)
+	c.HTTPClient = &httpClient
+}
+
+// insecureTransport returns a copy of rt skipping certificate verification.
+// Transports other than *http.Transport and debug transports wrapping one are returned as is.
+func insecureTransport(rt http.RoundTripper) http.RoundTripper {
+	switch t := rt.(type) {
+	case nil:
+		return insecureTransport(http.DefaultTransport)
+	case *debugTransport:
+		debug := *t
+		debug.next = insecureTransport(t.next)
+		return &debug
+	case *http.Transport:
+		if t.TLSClientConfig != nil && t.TLSClientConfig.InsecureSkipVerify {
+			return t
+		}
+		transport := t.Clone()
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.InsecureSkipVerify = true
+		return transport
+	}
+	return rt
+}
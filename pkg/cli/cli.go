@@ -1,14 +1,16 @@
 package cli
 
 import (
+	"flag"
 	"fmt"
-	"net/http"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 
 	"github.com/arvancloud/cli/pkg/api"
-	"github.com/arvancloud/cli/pkg/config"
+	"github.com/arvancloud/cli/pkg/options"
 	"github.com/arvancloud/cli/pkg/paas"
 	"github.com/arvancloud/cli/pkg/utl"
 	"github.com/inconshreveable/go-update"
@@ -34,37 +36,133 @@ var (
     To see the full list of commands supported, run 'arvan --help'.`
 )
 
-// NewCommandCLI return new cobra cli
-func NewCommandCLI() *cobra.Command {
-	// Load ConfigInfo from default path if exists
-	_, _ = config.LoadConfigFile()
+// NewCommandCLI return new cobra cli using dependencies provided by o
+func NewCommandCLI(o *options.Options) *cobra.Command {
+	// Load ConfigInfo from its path if exists
+	_, _ = o.Config.Load()
 
-	in, out, errout := os.Stdin, os.Stdout, os.Stderr
 	// Main command
 	cmd := &cobra.Command{
 		Use:   cliName,
 		Short: "Command line tools for managing Arvan services",
 		Long:  cliLong,
 		Run: func(c *cobra.Command, args []string) {
-			explainOut := term.NewResponsiveWriter(out)
+			explainOut := term.NewResponsiveWriter(o.Out)
 			c.SetOutput(explainOut)
 			fmt.Fprintf(explainOut, "%s\n\n%s\n", cliLong, cliExplain)
 		},
 	}
 
+	// errors returned by cobra are reported by Execute in the selected error format
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	cmd.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+		return utl.NewError(utl.KindValidation, err)
+	})
+
+	cmd.PersistentFlags().StringVar(&o.ErrorFormat, "error-format", utl.ErrorFormatText, "Format of printed errors. One of: text|json")
+
+	var verbose int
+	cmd.PersistentFlags().CountVar(&verbose, "verbose", "Log requests sent to arvan api to stderr. Repeat (--verbose --verbose) to dump bodies too")
+
 	optionsCommand := newCmdOptions()
 	cmd.AddCommand(optionsCommand)
 
-	loginCommand := paas.NewCmdLogin(in, out, errout)
+	loginCommand := paas.NewCmdLogin(o)
 	cmd.AddCommand(loginCommand)
 
-	paasCommand := paas.NewCmdPaas()
+	cmd.AddCommand(paas.NewCmdLogout(o))
+
+	cmd.AddCommand(paas.NewCmdWhoAmI(o))
+
+	cmd.AddCommand(paas.NewCmdApiKey(o))
+
+	paasCommand := paas.NewCmdPaas(o)
 	cmd.AddCommand(paasCommand)
 
-	cmd.AddCommand(updateCmd())
+	cmd.AddCommand(updateCmd(o))
+
+	cmd.PersistentPreRun = func(c *cobra.Command, args []string) {}
+	runBeforeCommands(cmd, func() {
+		o.CheckErr(utl.ValidateErrorFormat(o.ErrorFormat))
+		initDebug(o.Client, verbose, o.ErrOut)
+	})
 	return cmd
 }
 
+// Execute runs cmd and reports the error it returns through o.CheckErr.
+// Commands report their own failures through Options.CheckErr, so an untyped error returned by a command
+// without RunE comes from cobra, e.g an unknown command, missing arguments or required flags, and is reported as
+// a validation error.
+func Execute(cmd *cobra.Command, o *options.Options) {
+	c, err := cmd.ExecuteC()
+	if err == nil {
+		return
+	}
+	if c.RunE == nil && utl.KindOf(err) == utl.KindUnknown {
+		err = utl.NewError(utl.KindValidation, err)
+	}
+	if utl.KindOf(err) == utl.KindValidation && o.ErrorFormat != utl.ErrorFormatJSON {
+		err = utl.Errorf(utl.KindValidation, "%v\nSee '%s -h' for help and examples", err, c.CommandPath())
+	}
+	o.CheckErr(err)
+}
+
+// runBeforeCommands makes cmd and its subcommands call initialize before their persistent pre run.
+// Cobra only runs the persistent pre run of the nearest command defining one, so it's not enough to
+// only set the one of the root command.
+func runBeforeCommands(cmd *cobra.Command, initialize func()) {
+	if preRun := cmd.PersistentPreRun; preRun != nil {
+		cmd.PersistentPreRun = func(c *cobra.Command, args []string) {
+			initialize()
+			preRun(c, args)
+		}
+	}
+	if preRunE := cmd.PersistentPreRunE; preRunE != nil {
+		cmd.PersistentPreRunE = func(c *cobra.Command, args []string) error {
+			initialize()
+			return preRunE(c, args)
+		}
+	}
+	for _, c := range cmd.Commands() {
+		runBeforeCommands(c, initialize)
+	}
+}
+
+// klog verbosity levels at which kubectl starts logging request urls and bodies
+const (
+	klogRequestsLevel = 6
+	klogBodiesLevel   = 8
+)
+
+// initDebug enables http tracing of arvan api calls using the highest level requested by
+// --verbose, ARVAN_DEBUG or the klog --v flag accepted by paas commands.
+// It also raises klog verbosity so paas commands trace their own requests.
+func initDebug(client *api.Client, verbose int, out io.Writer) {
+	level := verbose
+	if envLevel := api.DebugLevelFromEnv(); envLevel > level {
+		level = envLevel
+	}
+
+	klogFlag := flag.CommandLine.Lookup("v")
+	if klogFlag != nil {
+		klogLevel, _ := strconv.Atoi(klogFlag.Value.String())
+		if klogLevel >= klogBodiesLevel && level < api.DebugBodies {
+			level = api.DebugBodies
+		} else if klogLevel >= klogRequestsLevel && level < api.DebugRequests {
+			level = api.DebugRequests
+		}
+
+		if level >= api.DebugBodies && klogLevel < klogBodiesLevel {
+			_ = klogFlag.Value.Set(strconv.Itoa(klogBodiesLevel))
+		} else if level >= api.DebugRequests && klogLevel < klogRequestsLevel {
+			_ = klogFlag.Value.Set(strconv.Itoa(klogRequestsLevel))
+		}
+	}
+
+	client.EnableDebug(level, out)
+}
+
 // newCmdOptions implements the OpenShift cli options command
 func newCmdOptions() *cobra.Command {
 	cmd := &cobra.Command{
@@ -78,43 +176,43 @@ func newCmdOptions() *cobra.Command {
 }
 
 // updateCmd updates cli
-func updateCmd() *cobra.Command {
+func updateCmd(o *options.Options) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "update",
 		Short: "Update arvan cli",
 		Run: func(cmd *cobra.Command, args []string) {
-			newVersion, err := api.CheckUpdate()
-			utl.CheckErr(err)
+			newVersion, err := o.Client.CheckUpdate()
+			o.CheckErr(err)
 			if newVersion == nil {
-				fmt.Println("arvan cli is up to date ")
+				fmt.Fprintln(o.Out, "arvan cli is up to date ")
 				return
 			}
-			fmt.Println("update started ...")
-			resp, err := http.Get(newVersion.URL)
+			fmt.Fprintln(o.Out, "update started ...")
+			resp, err := o.Client.HTTPClient.Get(newVersion.URL)
 			if err != nil {
-				utl.CheckErr(err)
+				o.CheckErr(utl.NewError(utl.KindNetwork, err))
 			}
 			defer resp.Body.Close()
 			var cliName string
 			if runtime.GOOS == "windows" {
 				_, err := utl.Unzip(os.TempDir(), resp.Body)
-				utl.CheckErr(err)
+				o.CheckErr(err)
 				cliName = "arvan.exe"
 			} else {
 				err = utl.Untar(os.TempDir(), resp.Body)
-				utl.CheckErr(err)
+				o.CheckErr(err)
 				cliName = "arvan"
 			}
 
 			reader, err := os.Open(filepath.Join(os.TempDir(), cliName))
-			utl.CheckErr(err)
+			o.CheckErr(err)
 			err = update.Apply(reader, update.Options{})
 			if err != nil {
 				update.RollbackError(err)
-				fmt.Println(err)
-				utl.CheckErr(fmt.Errorf("update failed :("))
+				fmt.Fprintln(o.ErrOut, err)
+				o.CheckErr(utl.Errorf(utl.KindUnknown, "update failed :("))
 			}
-			fmt.Println("update finished successfully :)")
+			fmt.Fprintln(o.Out, "update finished successfully :)")
 		},
 	}
 	return cmd
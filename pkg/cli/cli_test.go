@@ -0,0 +1,56 @@
+package cli_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/arvancloud/cli/pkg/cli/clitest"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+func TestUsageErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{name: "unknown flag", args: []string{"whoami", "--bogus"}, err: "unknown flag: --bogus"},
+		{name: "unexpected argument", args: []string{"paas", "region", "list", "extra"}, err: `unknown command "extra"`},
+		{name: "unknown command", args: []string{"bogus"}, err: `unknown command "bogus" for "arvan"`},
+		{name: "missing required flag", args: []string{"paas", "env", "push"}, err: `required flag(s) "to" not set`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := clitest.New(t).Run("", test.args...)
+			if result.ExitCode != utl.ValidationErrorExitCode {
+				t.Errorf("exit code = %d, want %d", result.ExitCode, utl.ValidationErrorExitCode)
+			}
+			if !strings.Contains(result.Stderr, test.err) || !strings.Contains(result.Stderr, "-h' for help and examples") {
+				t.Errorf("stderr = %q, want error containing %q and a help hint", result.Stderr, test.err)
+			}
+		})
+	}
+}
+
+func TestUsageErrorJSON(t *testing.T) {
+	result := clitest.New(t).Run("", "--error-format", "json", "whoami", "--bogus")
+	if result.ExitCode != utl.ValidationErrorExitCode {
+		t.Errorf("exit code = %d, want %d", result.ExitCode, utl.ValidationErrorExitCode)
+	}
+
+	var output struct {
+		Error struct {
+			Kind     utl.ErrorKind `json:"kind"`
+			Message  string        `json:"message"`
+			ExitCode int           `json:"exitCode"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(result.Stderr), &output); err != nil {
+		t.Fatalf("stderr is not a json error: %v\n%s", err, result.Stderr)
+	}
+	if output.Error.Kind != utl.KindValidation || output.Error.Message != "unknown flag: --bogus" {
+		t.Errorf("error = %+v, want validation error of the unknown flag", output.Error)
+	}
+}
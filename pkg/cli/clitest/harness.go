@@ -0,0 +1,195 @@
+// Package clitest runs arvan commands end-to-end against the fake api server of apitest
+// and compares their output with golden files.
+package clitest
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+
+	"github.com/arvancloud/cli/pkg/api/apitest"
+	"github.com/arvancloud/cli/pkg/cli"
+	"github.com/arvancloud/cli/pkg/config"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+// update makes AssertGolden rewrite golden files instead of comparing them, e.g "go test ./pkg/paas -update".
+var update = flag.Bool("update", false, "Rewrite golden files instead of comparing them")
+
+// oc commands exit through process wide fatal handlers, so only one command can run at a time.
+var runLock sync.Mutex
+
+// Harness runs arvan commands in a temporary home directory against a fake api server.
+type Harness struct {
+	t testing.TB
+
+	// Server is the fake api server commands send their requests to
+	Server *apitest.Server
+
+	// HomeDir is the temporary arvan home directory e.g where config and paasconfig are saved
+	HomeDir string
+
+	// Config is logged in to default zone of Server using its ApiKey
+	Config *config.ConfigInfo
+
+	// Clock is used by commands if set, e.g a fake clock to not wait between polls
+	Clock clock.Clock
+}
+
+// Result holds what a command wrote and the code it exited with.
+type Result struct {
+	Args     []string
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// exit is used to stop a command when it exits through Options.Exit or a fatal handler.
+type exit struct {
+	code int
+}
+
+// New returns a Harness logged in to a new fake api server. Both are cleaned up when the test finishes.
+func New(t testing.TB) *Harness {
+	t.Helper()
+
+	homeDir, err := ioutil.TempDir("", "arvan-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := apitest.NewServer()
+	t.Cleanup(func() {
+		server.Close()
+		_ = os.RemoveAll(homeDir)
+	})
+
+	arvanConfig, err := server.NewConfigInfo(homeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Harness{
+		t:       t,
+		Server:  server,
+		HomeDir: homeDir,
+		Config:  arvanConfig,
+	}
+}
+
+// Run executes arvan with args, feeding stdin to it.
+func (h *Harness) Run(stdin string, args ...string) Result {
+	h.t.Helper()
+
+	runLock.Lock()
+	defer runLock.Unlock()
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	result := Result{Args: args}
+
+	onFatal := func(msg string, code int) {
+		if len(msg) > 0 {
+			if !strings.HasSuffix(msg, "\n") {
+				msg += "\n"
+			}
+			stderr.WriteString(msg)
+		}
+		panic(exit{code})
+	}
+	utl.BehaviorOnFatal(onFatal)
+	kcmdutil.BehaviorOnFatal(onFatal)
+	defer utl.DefaultBehaviorOnFatal()
+	defer kcmdutil.DefaultBehaviorOnFatal()
+
+	o := h.Server.NewOptions(h.Config, strings.NewReader(stdin), stdout, stderr)
+	o.Exit = func(code int) {
+		panic(exit{code})
+	}
+	if h.Clock != nil {
+		o.Clock = h.Clock
+	}
+	cmd := cli.NewCommandCLI(o)
+	cmd.SetArgs(args)
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				e, ok := r.(exit)
+				if !ok {
+					panic(r)
+				}
+				result.ExitCode = e.code
+			}
+		}()
+		cli.Execute(cmd, o)
+	}()
+
+	result.Stdout = h.normalize(stdout.String())
+	result.Stderr = h.normalize(stderr.String())
+	return result
+}
+
+// AssertGolden compares result with testdata/<name>.golden of the running test package.
+// Run tests with -update to write result to the golden file instead.
+func (h *Harness) AssertGolden(name string, result Result) {
+	h.t.Helper()
+	AssertGolden(h.t, name, result.String())
+}
+
+// AssertGolden compares actual with testdata/<name>.golden of the running test package, e.g output of a
+// function rendering part of a command output. Run tests with -update to write actual to the golden file instead.
+func AssertGolden(t testing.TB, name, actual string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(actual), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v\nrun tests with -update to create it", err)
+	}
+	if string(expected) != actual {
+		t.Errorf("output does not match %s\n--- expected\n%s\n--- actual\n%s", path, expected, actual)
+	}
+}
+
+// String formats result the way it's stored in golden files.
+func (r Result) String() string {
+	return fmt.Sprintf("$ arvan %s\n--- stdout\n%s\n--- stderr\n%s\n--- exit code: %d\n",
+		strings.Join(r.Args, " "), r.Stdout, r.Stderr, r.ExitCode)
+}
+
+// normalize replaces values changing between runs, like the server address or home directory, with placeholders.
+func (h *Harness) normalize(output string) string {
+	serverURL, err := url.Parse(h.Server.URL())
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	replacer := strings.NewReplacer(
+		h.Server.URL(), "{{server}}",
+		serverURL.Host, "{{host}}",
+		strings.Replace(serverURL.Hostname(), ".", "-", -1)+":"+serverURL.Port(), "{{host}}",
+		h.HomeDir, "{{home}}",
+	)
+	return replacer.Replace(output)
+}
@@ -3,7 +3,7 @@ package config
 import (
 	"errors"
 	"io/ioutil"
-	"sync"
+	"time"
 
 	"github.com/arvancloud/cli/pkg/utl"
 
@@ -15,30 +15,30 @@ const (
 )
 
 type configFile struct {
-	ApiVersion string `yaml:"apiVersion"`
-	Server     string `yaml:"server"`
-	ApiKey     string `yaml:"apikey"`
-	Region     string `yaml:"region,omitempty"`
-}
-
-var instance *ConfigInfo
-var once sync.Once
+	ApiVersion   string `yaml:"apiVersion"`
+	Server       string `yaml:"server"`
+	ApiKey       string `yaml:"apikey"`
+	Region       string `yaml:"region,omitempty"`
+	AuthType     string `yaml:"authType,omitempty"`
+	Token        string `yaml:"token,omitempty"`
+	RefreshToken string `yaml:"refreshToken,omitempty"`
+	TokenExpiry  string `yaml:"tokenExpiry,omitempty"`
 
-// GetConfigInfo return ConfigInfo instance including the information about server url and authorization info
-func GetConfigInfo() *ConfigInfo {
-	once.Do(func() {
-		instance = &ConfigInfo{}
-		_ = instance.Complete()
-	})
-	return instance
+	KubeConfigAuth string `yaml:"kubeconfigAuth,omitempty"`
 }
 
-// LoadConfigFile load config info from ConfigFilePath into ConfigInfo which is accessible using GetConfigInfo()
-func LoadConfigFile() (bool, error) {
-	arvanConfig := GetConfigInfo()
+// NewConfigInfo returns a ConfigInfo saving its config file in homeDir.
+// If homeDir is empty, default home directory e.g /home/jane/.arvan is used.
+func NewConfigInfo(homeDir string) *ConfigInfo {
+	c := &ConfigInfo{homeDir: homeDir}
+	_ = c.Complete()
+	return c
+}
 
-	if arvanConfig.ConfigFileProvided() {
-		data, err := ioutil.ReadFile(arvanConfig.configFilePath)
+// Load loads config info from ConfigFilePath
+func (c *ConfigInfo) Load() (bool, error) {
+	if c.ConfigFileProvided() {
+		data, err := ioutil.ReadFile(c.configFilePath)
 		if err != nil {
 			return false, err
 		}
@@ -48,12 +48,23 @@ func LoadConfigFile() (bool, error) {
 			return false, err
 		}
 
-		arvanConfig.apiKey = configFileStruct.ApiKey
-		arvanConfig.server = configFileStruct.Server
+		c.apiKey = configFileStruct.ApiKey
+		c.server = configFileStruct.Server
+		c.authType = configFileStruct.AuthType
+		c.token = configFileStruct.Token
+		c.refreshToken = configFileStruct.RefreshToken
+		c.kubeConfigAuth = configFileStruct.KubeConfigAuth
+		c.tokenExpiry = time.Time{}
+		if len(configFileStruct.TokenExpiry) > 0 {
+			c.tokenExpiry, err = time.Parse(time.RFC3339, configFileStruct.TokenExpiry)
+			if err != nil {
+				return false, err
+			}
+		}
 
 		if configFileStruct.Region != "" {
-			arvanConfig.server = configFileStruct.Server + regionsEndpoint + configFileStruct.Region
-			_, err = arvanConfig.SaveConfig()
+			c.server = configFileStruct.Server + regionsEndpoint + configFileStruct.Region
+			_, err = c.SaveConfig()
 			utl.CheckErr(err)
 		}
 
@@ -2,15 +2,38 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"os/user"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v2"
 )
 
 const (
 	configFileApiVersion = "v1"
+	configFileMode       = 0600
+
+	bearerAuthorizationPrefix = "Bearer "
+)
+
+// Types of credentials used to authorize requests
+const (
+	// AuthTypeApiKey authorizes using an api key e.g "Apikey xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"
+	AuthTypeApiKey = "apikey"
+	// AuthTypeBearer authorizes using an oauth access token which can be refreshed
+	AuthTypeBearer = "bearer"
+	// AuthTypeServiceAccount authorizes using an openshift service account token, only valid for paas api
+	AuthTypeServiceAccount = "serviceaccount"
+)
+
+// Ways kubeconfig users of paas authorize
+const (
+	// KubeConfigAuthToken writes the token itself to kubeconfig users
+	KubeConfigAuthToken = "token"
+	// KubeConfigAuthExec makes kubeconfig users get the token from "arvan paas credential" on each use
+	KubeConfigAuthExec = "exec"
 )
 
 // ConfigInfo is a struct to access authorization information and global configurations of arvan cli save based on `arvan login` command.
@@ -21,6 +44,18 @@ type ConfigInfo struct {
 	// an api key used to authorize request to arvan api server
 	apiKey string
 
+	// type of credentials, one of AuthTypeApiKey, AuthTypeBearer or AuthTypeServiceAccount
+	authType string
+
+	// bearer or service account token used to authorize request instead of api key
+	token string
+
+	// oauth refresh token used to renew an expired bearer token
+	refreshToken string
+
+	// time bearer token expires at, zero if it never expires
+	tokenExpiry time.Time
+
 	// path to arvan config file e.g /home/jane/.arvan/config
 	configFilePath string
 
@@ -28,6 +63,9 @@ type ConfigInfo struct {
 	homeDir string
 
 	region string
+
+	// how kubeconfig users of paas authorize, one of KubeConfigAuthToken or KubeConfigAuthExec
+	kubeConfigAuth string
 }
 
 // GetServer returns base url to access arvan api server
@@ -50,9 +88,97 @@ func (c *ConfigInfo) GetHomeDir() string {
 	return c.homeDir
 }
 
+// GetAuthType returns type of credentials used to authorize requests
+func (c *ConfigInfo) GetAuthType() string {
+	if len(c.authType) == 0 {
+		return AuthTypeApiKey
+	}
+	return c.authType
+}
+
+// GetAuthorization returns value of Authorization header of requests sent to arvan api server
+func (c *ConfigInfo) GetAuthorization() string {
+	if c.GetAuthType() == AuthTypeApiKey {
+		return c.apiKey
+	}
+	if len(c.token) == 0 {
+		return ""
+	}
+	return bearerAuthorizationPrefix + c.token
+}
+
+// GetToken returns token written to kubeconfig users to access paas api server
+func (c *ConfigInfo) GetToken() string {
+	if c.GetAuthType() == AuthTypeApiKey {
+		return c.apiKey
+	}
+	return c.token
+}
+
+// GetRefreshToken returns oauth refresh token used to renew an expired bearer token
+func (c *ConfigInfo) GetRefreshToken() string {
+	return c.refreshToken
+}
+
+// GetTokenExpiry returns time bearer token expires at, zero if it never expires
+func (c *ConfigInfo) GetTokenExpiry() time.Time {
+	return c.tokenExpiry
+}
+
+// HasCredentials reports whether any credentials are provided
+func (c *ConfigInfo) HasCredentials() bool {
+	return len(c.GetAuthorization()) > 0
+}
+
+// Initiate sets server of zone, and api key if it's not empty which replaces any token credentials
 func (c *ConfigInfo) Initiate(apiKey string, zone Zone) {
 	c.server = "https://" + zone.Endpoint
-	c.apiKey = apiKey
+	if len(apiKey) > 0 || c.GetAuthType() == AuthTypeApiKey {
+		c.SetApiKey(apiKey)
+	}
+}
+
+// SetBearerToken replaces credentials with an oauth bearer token
+func (c *ConfigInfo) SetBearerToken(token, refreshToken string, expiry time.Time) {
+	c.apiKey = ""
+	c.authType = AuthTypeBearer
+	c.token = token
+	c.refreshToken = refreshToken
+	c.tokenExpiry = expiry
+}
+
+// SetServiceAccountToken replaces credentials with an openshift service account token
+func (c *ConfigInfo) SetServiceAccountToken(token string) {
+	c.apiKey = ""
+	c.authType = AuthTypeServiceAccount
+	c.token = token
+	c.refreshToken = ""
+	c.tokenExpiry = time.Time{}
+}
+
+// GetKubeConfigAuth returns how kubeconfig users of paas authorize
+func (c *ConfigInfo) GetKubeConfigAuth() string {
+	if len(c.kubeConfigAuth) == 0 {
+		return KubeConfigAuthToken
+	}
+	return c.kubeConfigAuth
+}
+
+// SetKubeConfigAuth sets how kubeconfig users of paas authorize, either KubeConfigAuthToken or KubeConfigAuthExec
+func (c *ConfigInfo) SetKubeConfigAuth(kubeConfigAuth string) error {
+	if err := ValidateKubeConfigAuth(kubeConfigAuth); err != nil {
+		return err
+	}
+	c.kubeConfigAuth = kubeConfigAuth
+	return nil
+}
+
+// ValidateKubeConfigAuth makes sure kubeConfigAuth is either KubeConfigAuthToken or KubeConfigAuthExec
+func ValidateKubeConfigAuth(kubeConfigAuth string) error {
+	if kubeConfigAuth != KubeConfigAuthToken && kubeConfigAuth != KubeConfigAuthExec {
+		return fmt.Errorf("invalid kubeconfig auth %q. One of: %s, %s", kubeConfigAuth, KubeConfigAuthToken, KubeConfigAuthExec)
+	}
+	return nil
 }
 
 func (c *ConfigInfo) Complete() error {
@@ -86,18 +212,35 @@ func (c *ConfigInfo) SaveConfig() (bool, error) {
 			return false, err
 		}
 	}
-	file, err := os.Create(c.configFilePath)
+	// config holds credentials, so it's only readable by its owner
+	file, err := os.OpenFile(c.configFilePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, configFileMode)
 	if err != nil {
 		return false, err
 	}
 
 	defer file.Close()
 
+	// mode of OpenFile only applies to new files, config files written by previous versions are readable by others
+	if err = file.Chmod(configFileMode); err != nil {
+		return false, err
+	}
+
 	configFileStruct := configFile{
-		ApiVersion: configFileApiVersion,
-		Server:     c.server,
-		ApiKey:     c.apiKey,
-		Region:     c.region,
+		ApiVersion:   configFileApiVersion,
+		Server:       c.server,
+		ApiKey:       c.apiKey,
+		Region:       c.region,
+		Token:        c.token,
+		RefreshToken: c.refreshToken,
+	}
+	if c.GetAuthType() != AuthTypeApiKey {
+		configFileStruct.AuthType = c.authType
+	}
+	if c.GetKubeConfigAuth() != KubeConfigAuthToken {
+		configFileStruct.KubeConfigAuth = c.kubeConfigAuth
+	}
+	if !c.tokenExpiry.IsZero() {
+		configFileStruct.TokenExpiry = c.tokenExpiry.UTC().Format(time.RFC3339)
 	}
 
 	configFileStr, err := yaml.Marshal(&configFileStruct)
@@ -112,6 +255,38 @@ func (c *ConfigInfo) SaveConfig() (bool, error) {
 	return true, nil
 }
 
+// SetApiKey replaces credentials with an api key used to authorize request to arvan api server
+func (c *ConfigInfo) SetApiKey(apiKey string) {
+	c.apiKey = apiKey
+	c.authType = AuthTypeApiKey
+	c.token = ""
+	c.refreshToken = ""
+	c.tokenExpiry = time.Time{}
+}
+
+// ClearCredentials removes api key and tokens from config info and saves it to ConfigFilePath
+func (c *ConfigInfo) ClearCredentials() error {
+	c.SetApiKey("")
+	_, err := c.SaveConfig()
+	return err
+}
+
+// RemoveConfig removes config file and resets config info to defaults
+func (c *ConfigInfo) RemoveConfig() error {
+	c.SetApiKey("")
+	c.server = ""
+	c.region = ""
+	c.kubeConfigAuth = ""
+	if err := c.Complete(); err != nil {
+		return err
+	}
+	err := os.Remove(c.configFilePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
 func (c *ConfigInfo) ServerProvided() bool {
 	return len(c.server) > 0
 }
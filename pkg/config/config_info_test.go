@@ -0,0 +1,46 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestSaveConfigMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not supported on windows")
+	}
+
+	tests := []struct {
+		name     string
+		existing bool
+	}{
+		{name: "new"},
+		{name: "existing", existing: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewConfigInfo(t.TempDir())
+			if test.existing {
+				if err := ioutil.WriteFile(c.GetConfigFilePath(), []byte("apiVersion: v1\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			c.SetApiKey("Apikey 00000000-0000-0000-0000-000000000000")
+			if _, err := c.SaveConfig(); err != nil {
+				t.Fatal(err)
+			}
+
+			info, err := os.Stat(c.GetConfigFilePath())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mode := info.Mode().Perm(); mode != configFileMode {
+				t.Errorf("mode of config file = %v, want %v", mode, os.FileMode(configFileMode))
+			}
+		})
+	}
+}
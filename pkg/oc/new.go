@@ -15,6 +15,7 @@ import (
 	"github.com/openshift/api/user"
 	"github.com/openshift/oc/pkg/helpers/legacy"
 	"github.com/spf13/cobra"
+	"io"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
@@ -24,7 +25,8 @@ import (
 	"path/filepath"
 )
 
-func InitiatedCommand() *cobra.Command {
+// InitiatedCommand registers openshift types and returns the oc command tree reading from in and writing to out and errout.
+func InitiatedCommand(in io.Reader, out, errout io.Writer) *cobra.Command {
 	// the kubectl scheme expects to have all the recognizable external types it needs to consume.  Install those here.
 	// We can't use the "normal" scheme because apply will use that to build stategic merge patches on CustomResources
 	utilruntime.Must(apps.Install(scheme.Scheme))
@@ -42,7 +44,7 @@ func InitiatedCommand() *cobra.Command {
 	legacy.InstallExternalLegacyAll(scheme.Scheme)
 
 	basename := filepath.Base(os.Args[0])
-	return CommandFor(basename)
+	return CommandFor(basename, in, out, errout)
 }
 
 func installNonCRDSecurity(scheme *apimachineryruntime.Scheme) error {
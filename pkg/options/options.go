@@ -0,0 +1,78 @@
+package options
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/arvancloud/cli/pkg/api"
+	"github.com/arvancloud/cli/pkg/config"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+// Options holds dependencies of arvan cli commands.
+// Commands constructed using the same Options share them, which allows embedding the cli in-process
+// with custom config, api client or IO streams.
+type Options struct {
+	// Config holds server url and authorization info
+	Config *config.ConfigInfo
+
+	// Client sends requests to arvan api server
+	Client *api.Client
+
+	// In is where commands read user input from
+	In io.Reader
+
+	// Out is where commands write their output to
+	Out io.Writer
+
+	// ErrOut is where commands write errors and warnings to
+	ErrOut io.Writer
+
+	// Clock is used for polling and timing
+	Clock clock.Clock
+
+	// ErrorFormat is the format CheckErr prints errors in; either utl.ErrorFormatText or utl.ErrorFormatJSON
+	ErrorFormat string
+
+	// Exit ends the command with an exit code after CheckErr reported an error, or when a command reports
+	// its result by the exit code. Defaults to os.Exit; replace it to keep the process running when embedding the cli.
+	Exit func(code int)
+}
+
+// NewDefaultOptions returns Options using ConfigInfo of default home directory, standard IO streams and real clock.
+func NewDefaultOptions() *Options {
+	return NewOptions(config.NewConfigInfo(""), os.Stdin, os.Stdout, os.Stderr)
+}
+
+// NewOptions returns Options using arvanConfig, a client accessing its api server and the given IO streams.
+func NewOptions(arvanConfig *config.ConfigInfo, in io.Reader, out, errout io.Writer) *Options {
+	return &Options{
+		Config: arvanConfig,
+		Client: api.NewClient(arvanConfig),
+		In:     in,
+		Out:    out,
+		ErrOut: errout,
+		Clock:  clock.RealClock{},
+		Exit:   os.Exit,
+
+		ErrorFormat: utl.ErrorFormatText,
+	}
+}
+
+// CheckErr prints err in ErrorFormat to ErrOut and calls Exit with the exit code matching its kind.
+// It does nothing if err is nil.
+func (o *Options) CheckErr(err error) {
+	if err == nil {
+		return
+	}
+	msg := utl.FormatErr(err, o.ErrorFormat)
+	if !strings.HasSuffix(msg, "\n") {
+		msg += "\n"
+	}
+	fmt.Fprint(o.ErrOut, msg)
+	o.Exit(utl.ExitCode(err))
+}
@@ -0,0 +1,51 @@
+package options
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/arvancloud/cli/pkg/config"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+func TestCheckErr(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		err      error
+		errOut   string
+		exitCode int
+	}{
+		{name: "nil", format: utl.ErrorFormatText, err: nil, errOut: "", exitCode: -1},
+		{name: "text", format: utl.ErrorFormatText, err: utl.Errorf(utl.KindAuth, "invalid key"), errOut: "invalid key\n", exitCode: utl.AuthErrorExitCode},
+		{name: "untyped", format: utl.ErrorFormatText, err: errors.New("failed\n"), errOut: "failed\n", exitCode: utl.DefaultErrorExitCode},
+		{
+			name:     "json",
+			format:   utl.ErrorFormatJSON,
+			err:      utl.Errorf(utl.KindNotFound, "no project"),
+			errOut:   `{"error":{"exitCode":5,"kind":"not-found","message":"no project"}}` + "\n",
+			exitCode: utl.NotFoundErrorExitCode,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errOut := &bytes.Buffer{}
+			o := NewOptions(config.NewConfigInfo(t.TempDir()), &bytes.Buffer{}, &bytes.Buffer{}, errOut)
+			o.ErrorFormat = test.format
+			exitCode := -1
+			o.Exit = func(code int) {
+				exitCode = code
+			}
+
+			o.CheckErr(test.err)
+			if errOut.String() != test.errOut {
+				t.Errorf("ErrOut = %q, want %q", errOut, test.errOut)
+			}
+			if exitCode != test.exitCode {
+				t.Errorf("exit code = %d, want %d", exitCode, test.exitCode)
+			}
+		})
+	}
+}
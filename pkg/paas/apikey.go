@@ -0,0 +1,195 @@
+package paas
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arvancloud/cli/pkg/api"
+	"github.com/arvancloud/cli/pkg/config"
+	"github.com/arvancloud/cli/pkg/options"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+var (
+	apiKeyLong = `
+    Manage API keys of your Arvan account
+
+    Use "arvan apikey rotate" to replace the key arvan cli is logged in with by a new one,
+    e.g. in scheduled rotations.`
+)
+
+// NewCmdApiKey returns new cobra commad enables user to manage api keys
+func NewCmdApiKey(o *options.Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apikey",
+		Short: "Manage API keys",
+		Long:  apiKeyLong,
+		Run: func(c *cobra.Command, args []string) {
+			c.SetOutput(o.Out)
+			_ = c.Help()
+		},
+	}
+
+	cmd.AddCommand(newCmdApiKeyList(o))
+	cmd.AddCommand(newCmdApiKeyCreate(o))
+	cmd.AddCommand(newCmdApiKeyRevoke(o))
+	cmd.AddCommand(newCmdApiKeyRotate(o))
+
+	return cmd
+}
+
+func newCmdApiKeyList(o *options.Options) *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List API keys",
+		Args:  cobra.NoArgs,
+		Run: func(c *cobra.Command, args []string) {
+			o.CheckErr(validateOutputFormat(output))
+			o.CheckErr(requireCredentials(o))
+
+			apiKeys, err := o.Client.ListApiKeys()
+			o.CheckErr(err)
+
+			if output == outputFormatJSON {
+				o.CheckErr(printJSON(o.Out, apiKeys))
+				return
+			}
+			sprintApiKeys(o.Out, apiKeys)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format. One of: json")
+
+	return cmd
+}
+
+func newCmdApiKeyCreate(o *options.Options) *cobra.Command {
+	var description string
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a new API key",
+		Args:  cobra.NoArgs,
+		Run: func(c *cobra.Command, args []string) {
+			o.CheckErr(requireCredentials(o))
+
+			apiKey, err := o.Client.CreateApiKey(description)
+			o.CheckErr(err)
+
+			fmt.Fprintf(o.Out, "API key %s created. Store it safely, it won't be shown again:\n%s\n", apiKey.ID, apiKey.Key)
+		},
+	}
+
+	cmd.Flags().StringVar(&description, "description", "", "Description of the new API key")
+
+	return cmd
+}
+
+func newCmdApiKeyRevoke(o *options.Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revoke ID",
+		Short: "Revoke an API key",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) != 1 {
+				o.CheckErr(utl.Errorf(utl.KindValidation, "API key ID is required. See 'arvan apikey list'"))
+			}
+			o.CheckErr(requireCredentials(o))
+
+			o.CheckErr(o.Client.RevokeApiKey(args[0]))
+
+			fmt.Fprintf(o.Out, "API key %s revoked.\n", args[0])
+		},
+	}
+
+	return cmd
+}
+
+func newCmdApiKeyRotate(o *options.Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Replace current API key with a new one",
+		Long: `
+    Create a new API key, use it in arvan config and paas kubeconfig, validate it,
+    and then revoke the old one.`,
+		Args: cobra.NoArgs,
+		Run: func(c *cobra.Command, args []string) {
+			o.CheckErr(requireCredentials(o))
+
+			newApiKey, err := rotateApiKey(o)
+			o.CheckErr(err)
+
+			fmt.Fprintf(o.Out, "API key rotated successfully. New key fingerprint: %s\n", keyFingerprint(newApiKey.Key))
+		},
+	}
+
+	return cmd
+}
+
+// rotateApiKey replaces api key of config with a new one and revokes the old one.
+// Old key is kept if the new one could not be validated.
+func rotateApiKey(o *options.Options) (*api.ApiKey, error) {
+	arvanConfig := o.Config
+	if arvanConfig.GetAuthType() != config.AuthTypeApiKey {
+		return nil, utl.Errorf(utl.KindValidation, "logged in using a %s token, only API keys can be rotated", arvanConfig.GetAuthType())
+	}
+	oldKey := arvanConfig.GetApiKey()
+
+	newApiKey, err := o.Client.CreateApiKey(fmt.Sprintf("rotated by arvan cli at %s", o.Clock.Now().UTC().Format(time.RFC3339)))
+	if err != nil {
+		return nil, err
+	}
+
+	arvanConfig.SetApiKey(newApiKey.Key)
+	if _, err = arvanConfig.SaveConfig(); err != nil {
+		arvanConfig.SetApiKey(oldKey)
+		return nil, err
+	}
+
+	if _, err = isAuthorized(o.Client, newApiKey.Key); err != nil {
+		arvanConfig.SetApiKey(oldKey)
+		if _, saveErr := arvanConfig.SaveConfig(); saveErr != nil {
+			return nil, saveErr
+		}
+		_ = o.Client.RevokeApiKey(newApiKey.ID)
+		return nil, fmt.Errorf("new API key is not valid, kept the old one: %w", err)
+	}
+
+	if err = replaceKubeConfigToken(paasConfigPath(arvanConfig), oldKey, newApiKey.Key); err != nil {
+		return nil, err
+	}
+
+	if err = o.Client.RevokeCurrentApiKey(oldKey); err != nil {
+		return nil, utl.NewError(utl.KindOf(err), fmt.Errorf("new API key is in use but the old one could not be revoked: %w", err))
+	}
+
+	return newApiKey, nil
+}
+
+// requireCredentials makes sure user is logged in, refreshing an expired bearer token.
+func requireCredentials(o *options.Options) error {
+	if !o.Config.HasCredentials() {
+		return utl.NewError(utl.KindAuth, errors.New("no authorization credentials provided. \nTry \"arvan login\""))
+	}
+	return o.Client.EnsureFreshToken(o.Clock.Now())
+}
+
+// sprintApiKeys displays api keys in columns.
+func sprintApiKeys(out io.Writer, apiKeys []api.ApiKey) {
+	w := new(tabwriter.Writer)
+	w.Init(out, 0, 8, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "ID\tDESCRIPTION\tCREATED\tLAST USED")
+	for _, apiKey := range apiKeys {
+		lastUsed := "never"
+		if apiKey.LastUsedAt != nil {
+			lastUsed = apiKey.LastUsedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", apiKey.ID, apiKey.Description, apiKey.CreatedAt.Format(time.RFC3339), lastUsed)
+	}
+}
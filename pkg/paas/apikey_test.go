@@ -0,0 +1,127 @@
+package paas_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvancloud/cli/pkg/api"
+	"github.com/arvancloud/cli/pkg/api/apitest"
+	"github.com/arvancloud/cli/pkg/cli/clitest"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+// syncKubeConfig runs a paas command so paas kubeconfig of h holds a user of the current credentials.
+func syncKubeConfig(t *testing.T, h *clitest.Harness) {
+	t.Helper()
+	if result := h.Run("", "paas", "region", "current"); result.ExitCode != 0 {
+		t.Fatalf("paas command failed:\n%s", result)
+	}
+}
+
+func TestApiKeyRotate(t *testing.T) {
+	h := clitest.New(t)
+	h.Server.Projects["at1"] = []string{"web"}
+	syncKubeConfig(t, h)
+
+	result := h.Run("", "apikey", "rotate")
+	if result.ExitCode != 0 {
+		t.Fatalf("rotate failed:\n%s", result)
+	}
+
+	if len(h.Server.ApiKeys) != 1 {
+		t.Fatalf("api keys = %+v, want the new key only", h.Server.ApiKeys)
+	}
+	newKey := h.Server.ApiKeys[0].Key
+	if apiKey := savedConfig(t, h).GetApiKey(); apiKey != newKey {
+		t.Errorf("saved api key = %q, want new key %q", apiKey, newKey)
+	}
+	if len(h.Server.ApiKey) > 0 {
+		t.Errorf("old api key is not revoked")
+	}
+
+	validated := false
+	for _, request := range h.Server.Requests() {
+		validated = validated || request == "GET /g/user"
+	}
+	if !validated {
+		t.Errorf("new key is not validated, requests: %v", h.Server.Requests())
+	}
+
+	for _, user := range loadKubeConfig(t, h).Users {
+		if user.User.Token != newKey {
+			t.Errorf("token of kubeconfig user %s = %q, want new key", user.Name, user.User.Token)
+		}
+	}
+	if strings.Contains(result.Stdout, newKey) {
+		t.Errorf("new key is printed:\n%s", result)
+	}
+}
+
+func TestApiKeyRotateInvalidNewKey(t *testing.T) {
+	h := clitest.New(t)
+	h.Server.Projects["at1"] = []string{"web"}
+	syncKubeConfig(t, h)
+	h.Server.RejectApiKeys = true
+
+	result := h.Run("", "apikey", "rotate")
+	if result.ExitCode != utl.AuthErrorExitCode {
+		t.Fatalf("exit code = %d, want %d:\n%s", result.ExitCode, utl.AuthErrorExitCode, result)
+	}
+	if !strings.Contains(result.Stderr, "kept the old one") {
+		t.Errorf("error does not report keeping the old key:\n%s", result)
+	}
+
+	if h.Server.ApiKey != apitest.DefaultApiKey {
+		t.Errorf("old api key is revoked")
+	}
+	if len(h.Server.ApiKeys) != 0 {
+		t.Errorf("api keys = %+v, want the invalid new key to be revoked", h.Server.ApiKeys)
+	}
+	if apiKey := savedConfig(t, h).GetApiKey(); apiKey != apitest.DefaultApiKey {
+		t.Errorf("saved api key = %q, want old key to be kept", apiKey)
+	}
+	for _, user := range loadKubeConfig(t, h).Users {
+		if user.User.Token != apitest.DefaultApiKey {
+			t.Errorf("token of kubeconfig user %s = %q, want old key to be kept", user.Name, user.User.Token)
+		}
+	}
+}
+
+func TestApiKeyListCreateRevoke(t *testing.T) {
+	h := clitest.New(t)
+	h.Server.ApiKeys = []api.ApiKey{{ID: "key-1", Description: "ci", CreatedAt: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)}}
+
+	result := h.Run("", "apikey", "create", "--description", "deploy")
+	if result.ExitCode != 0 {
+		t.Fatalf("create failed:\n%s", result)
+	}
+	created := h.Server.ApiKeys[1]
+	if created.Description != "deploy" || !strings.Contains(result.Stdout, created.Key) {
+		t.Errorf("created key %+v is not printed:\n%s", created, result)
+	}
+
+	result = h.Run("", "apikey", "list")
+	if result.ExitCode != 0 {
+		t.Fatalf("list failed:\n%s", result)
+	}
+	for _, expected := range []string{"key-1", "ci", "2021-01-02T03:04:05Z", "never", created.ID, "deploy"} {
+		if !strings.Contains(result.Stdout, expected) {
+			t.Errorf("list output does not contain %q:\n%s", expected, result)
+		}
+	}
+	if strings.Contains(result.Stdout, created.Key) {
+		t.Errorf("list output contains a key:\n%s", result)
+	}
+
+	if result = h.Run("", "apikey", "revoke", "key-1"); result.ExitCode != 0 {
+		t.Fatalf("revoke failed:\n%s", result)
+	}
+	if len(h.Server.ApiKeys) != 1 || h.Server.ApiKeys[0].ID != created.ID {
+		t.Errorf("api keys = %+v, want key-1 to be revoked", h.Server.ApiKeys)
+	}
+
+	if result = h.Run("", "apikey", "revoke", "key-1"); result.ExitCode != utl.NotFoundErrorExitCode {
+		t.Errorf("exit code of revoking a missing key = %d, want %d:\n%s", result.ExitCode, utl.NotFoundErrorExitCode, result)
+	}
+}
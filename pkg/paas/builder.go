@@ -0,0 +1,139 @@
+package paas
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arvancloud/cli/pkg/options"
+)
+
+const (
+	arvanBuilderFlag = "arvan-builder"
+	arvanBuilderAuto = "auto"
+	arvanBuilderNone = "none"
+
+	// arvanBuilderImage builds sources of any language
+	arvanBuilderImage = "arvanbuilder:ArvanBuilder"
+)
+
+// languageBuilders maps files marking language of a source directory to image stream building it.
+// Files are checked in order.
+var languageBuilders = []struct {
+	pattern string
+	builder string
+}{
+	{"package.json", "nodejs"},
+	{"requirements.txt", "python"},
+	{"setup.py", "python"},
+	{"Pipfile", "python"},
+	{"go.mod", "golang"},
+	{"pom.xml", "java"},
+	{"build.gradle", "java"},
+	{"composer.json", "php"},
+	{"index.php", "php"},
+	{"Gemfile", "ruby"},
+	{"*.csproj", "dotnet"},
+}
+
+// addNewAppFlags adds --arvan-builder to new-app command of paasCommand.
+func addNewAppFlags(paasCommand *cobra.Command) {
+	for _, c := range paasCommand.Commands() {
+		if c.Name() == "new-app" {
+			c.Flags().String(arvanBuilderFlag, arvanBuilderAuto, "Builder of source repositories. One of: auto, none, or an image stream e.g python:3.9. "+
+				"'auto' uses the builder of the language detected in local directories and ArvanBuilder for remote repositories")
+			return
+		}
+	}
+}
+
+// setArvanBuilder prefixes source repository arguments of new-app with the builder selected by --arvan-builder,
+// printing each rewritten argument.
+func setArvanBuilder(o *options.Options, cmd *cobra.Command) error {
+	if cmd.Name() != "new-app" {
+		return nil
+	}
+	builder, err := cmd.Flags().GetString(arvanBuilderFlag)
+	if err != nil {
+		builder = arvanBuilderAuto
+	}
+
+	args := cmd.Flags().Args()
+	rewritten := rewriteNewAppArgs(args, builder, detectBuilder)
+	for i := range args {
+		if rewritten[i] != args[i] {
+			fmt.Fprintf(o.ErrOut, "Using builder: %s\n", rewritten[i])
+			// new-app reads the same arguments
+			args[i] = rewritten[i]
+		}
+	}
+	return nil
+}
+
+// rewriteNewAppArgs returns args of new-app with source repositories prefixed by builder e.g "python~https://example.com/app.git".
+// If builder is "auto", detect returns builder of local directories, and ArvanBuilder is used for remote repositories or
+// when detect returns empty. Arguments already naming a builder, templates, images or parameters are kept.
+func rewriteNewAppArgs(args []string, builder string, detect func(dir string) string) []string {
+	rewritten := make([]string, len(args))
+	copy(rewritten, args)
+	if builder == arvanBuilderNone {
+		return rewritten
+	}
+
+	for i, arg := range args {
+		if strings.Contains(arg, "~") || strings.Contains(arg, "=") {
+			continue
+		}
+
+		remote := isRemoteRepository(arg)
+		if !remote && !isLocalDirectory(arg) {
+			continue
+		}
+
+		argBuilder := builder
+		if builder == arvanBuilderAuto {
+			argBuilder = arvanBuilderImage
+			if !remote {
+				if detected := detect(arg); len(detected) > 0 {
+					argBuilder = detected
+				}
+			}
+		}
+		rewritten[i] = argBuilder + "~" + arg
+	}
+	return rewritten
+}
+
+// detectBuilder returns image stream building language of sources in dir, or empty if it's unknown.
+func detectBuilder(dir string) string {
+	for _, lb := range languageBuilders {
+		matches, err := filepath.Glob(filepath.Join(dir, lb.pattern))
+		if err == nil && len(matches) > 0 {
+			return lb.builder
+		}
+	}
+	return ""
+}
+
+// imageStreamTag returns builder with the latest tag if it names an image stream without a tag, e.g "nodejs:latest" for "nodejs".
+func imageStreamTag(builder string) string {
+	if strings.Contains(builder, ":") {
+		return builder
+	}
+	return builder + ":latest"
+}
+
+// isRemoteRepository reports whether arg is url of a git repository e.g https://example.com/app.git or git@example.com:app.git.
+func isRemoteRepository(arg string) bool {
+	return strings.HasPrefix(arg, "https://") || strings.HasPrefix(arg, "http://") ||
+		strings.HasPrefix(arg, "git://") || strings.HasPrefix(arg, "ssh://") || strings.HasPrefix(arg, "git@")
+}
+
+// isLocalDirectory reports whether arg is path of an existing directory.
+func isLocalDirectory(arg string) bool {
+	info, err := os.Stat(arg)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,99 @@
+package paas
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRewriteNewAppArgs(t *testing.T) {
+	dir := t.TempDir()
+	otherDir := t.TempDir()
+	detect := func(d string) string {
+		if d == dir {
+			return "python"
+		}
+		return ""
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		builder  string
+		expected []string
+	}{
+		{
+			name:     "none keeps every argument",
+			args:     []string{"https://example.com/app.git", dir},
+			builder:  arvanBuilderNone,
+			expected: []string{"https://example.com/app.git", dir},
+		},
+		{
+			name:     "auto uses ArvanBuilder for urls",
+			args:     []string{"https://example.com/app.git"},
+			builder:  arvanBuilderAuto,
+			expected: []string{arvanBuilderImage + "~https://example.com/app.git"},
+		},
+		{
+			name:     "auto uses ArvanBuilder for git urls",
+			args:     []string{"git@example.com:app.git"},
+			builder:  arvanBuilderAuto,
+			expected: []string{arvanBuilderImage + "~git@example.com:app.git"},
+		},
+		{
+			name:     "auto uses detected builder of directories",
+			args:     []string{dir},
+			builder:  arvanBuilderAuto,
+			expected: []string{"python~" + dir},
+		},
+		{
+			name:     "auto uses ArvanBuilder for undetected directories",
+			args:     []string{otherDir},
+			builder:  arvanBuilderAuto,
+			expected: []string{arvanBuilderImage + "~" + otherDir},
+		},
+		{
+			name:     "image stream is used for urls and directories",
+			args:     []string{"https://example.com/app.git", dir},
+			builder:  "nodejs:14",
+			expected: []string{"nodejs:14~https://example.com/app.git", "nodejs:14~" + dir},
+		},
+		{
+			name:     "non url arguments are kept",
+			args:     []string{"mysql", "openshift/template", "./missing"},
+			builder:  arvanBuilderAuto,
+			expected: []string{"mysql", "openshift/template", "./missing"},
+		},
+		{
+			name:     "arguments naming a builder or a parameter are kept",
+			args:     []string{"php~https://example.com/app.git", "NAME=https://example.com"},
+			builder:  "nodejs:14",
+			expected: []string{"php~https://example.com/app.git", "NAME=https://example.com"},
+		},
+		{
+			name:    "sources in odd positions",
+			args:    []string{"mysql", "https://example.com/app.git", "DEBUG=1", dir, "redis", "http://example.com/api.git"},
+			builder: arvanBuilderAuto,
+			expected: []string{"mysql", arvanBuilderImage + "~https://example.com/app.git", "DEBUG=1", "python~" + dir, "redis",
+				arvanBuilderImage + "~http://example.com/api.git"},
+		},
+		{
+			name:     "no arguments",
+			args:     []string{},
+			builder:  arvanBuilderAuto,
+			expected: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			original := append([]string(nil), test.args...)
+			actual := rewriteNewAppArgs(test.args, test.builder, detect)
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("rewriteNewAppArgs(%q, %q) = %q, want %q", test.args, test.builder, actual, test.expected)
+			}
+			if !reflect.DeepEqual(test.args, original) {
+				t.Errorf("rewriteNewAppArgs modified its arguments to %q", test.args)
+			}
+		})
+	}
+}
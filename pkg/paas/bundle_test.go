@@ -0,0 +1,138 @@
+package paas
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStripGeneratedFieldsOfService(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			name:     "allocated cluster IP",
+			spec:     map[string]interface{}{"clusterIP": "172.30.12.5", "clusterIPs": []interface{}{"172.30.12.5"}, "type": "ClusterIP"},
+			expected: map[string]interface{}{"type": "ClusterIP"},
+		},
+		{
+			name:     "headless",
+			spec:     map[string]interface{}{"clusterIP": "None", "clusterIPs": []interface{}{"None"}},
+			expected: map[string]interface{}{"clusterIP": "None", "clusterIPs": []interface{}{"None"}},
+		},
+		{
+			name:     "headless without cluster IPs",
+			spec:     map[string]interface{}{"clusterIP": "None"},
+			expected: map[string]interface{}{"clusterIP": "None"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := object{
+				"kind":     "Service",
+				"metadata": map[string]interface{}{"name": "db", "uid": "1234", "resourceVersion": "42"},
+				"spec":     test.spec,
+				"status":   map[string]interface{}{},
+			}
+			stripGeneratedFields(service, false)
+
+			expected := object{"kind": "Service", "metadata": map[string]interface{}{"name": "db"}, "spec": test.expected}
+			if !reflect.DeepEqual(service, expected) {
+				t.Errorf("stripped service = %v, want %v", service, expected)
+			}
+		})
+	}
+}
+
+func TestGetBundleKind(t *testing.T) {
+	if k, err := getBundleKind("Route"); err != nil || k.resource != "routes" {
+		t.Errorf("getBundleKind(Route) = %v, %v, want routes", k, err)
+	}
+	if _, err := getBundleKind("Pod"); err == nil {
+		t.Error("expected an error getting a kind missing from bundles")
+	}
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	configMap := object{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "settings"}, "data": map[string]interface{}{"mode": "production"}}
+	route := object{"apiVersion": "route.openshift.io/v1", "kind": "Route", "metadata": map[string]interface{}{"name": "web"}}
+	objects := make([][]object, len(bundleKinds))
+	objects[len(bundleKinds)-1] = []object{route}
+	objects[0] = []object{configMap}
+	info := BundleInfo{ApiVersion: bundleApiVersion, Project: "shop", Region: "ir-thr-at1", ExportedAt: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	var bundle bytes.Buffer
+	if err := writeBundle(&bundle, info, objects); err != nil {
+		t.Fatal(err)
+	}
+	readInfo, data, err := readBundle(&bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(*readInfo, info) {
+		t.Errorf("bundle info = %+v, want %+v", *readInfo, info)
+	}
+	expected, err := marshalObjects([]object{configMap, route})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(expected) {
+		t.Errorf("bundle resources =\n%s\nwant\n%s", data, expected)
+	}
+}
+
+func TestReadBundleInvalid(t *testing.T) {
+	writeInfo := func(info BundleInfo, objects [][]object) []byte {
+		var bundle bytes.Buffer
+		if err := writeBundle(&bundle, info, objects); err != nil {
+			t.Fatal(err)
+		}
+		return bundle.Bytes()
+	}
+	configMaps := make([][]object, len(bundleKinds))
+	configMaps[0] = []object{{"kind": "ConfigMap", "metadata": map[string]interface{}{"name": "settings"}}}
+
+	tests := []struct {
+		name   string
+		bundle []byte
+		err    string
+	}{
+		{name: "not gzipped", bundle: []byte("kind: ConfigMap"), err: "invalid bundle"},
+		{name: "unsupported version", bundle: writeInfo(BundleInfo{ApiVersion: "v2", Project: "shop"}, configMaps), err: "unsupported bundle apiVersion"},
+		{name: "empty", bundle: writeInfo(BundleInfo{ApiVersion: bundleApiVersion, Project: "shop"}, nil), err: "has no resources"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, _, err := readBundle(bytes.NewReader(test.bundle)); err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("error = %v, want %q", err, test.err)
+			}
+		})
+	}
+}
+
+func TestSetObjectsNamespace(t *testing.T) {
+	data, err := marshalObjects([]object{
+		{"kind": "ConfigMap", "metadata": map[string]interface{}{"name": "settings"}},
+		{"kind": "Service", "metadata": map[string]interface{}{"name": "web", "namespace": "shop"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rewritten, err := setObjectsNamespace(data, "shop-staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "---\nkind: ConfigMap\nmetadata:\n  name: settings\n  namespace: shop-staging\n" +
+		"---\nkind: Service\nmetadata:\n  name: web\n  namespace: shop-staging\n"
+	if string(rewritten) != expected {
+		t.Errorf("rewritten resources =\n%s\nwant\n%s", rewritten, expected)
+	}
+}
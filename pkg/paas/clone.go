@@ -0,0 +1,307 @@
+package paas
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arvancloud/cli/pkg/config"
+	"github.com/arvancloud/cli/pkg/options"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+const (
+	applyPatchContentType = "application/apply-patch+yaml"
+	fieldManager          = "arvan-cli"
+
+	// dataCopyImage runs temporary pods mounting volumes whose contents are copied by "oc rsync"
+	dataCopyImage     = "alpine:3.14"
+	dataCopyMountPath = "/data"
+	dataCopyPodPrefix = "arvan-clone-"
+	dataCopyTimeout   = 5 * time.Minute
+	podPhasePending   = "Pending"
+
+	internalRegistry = "image-registry.openshift-image-registry.svc:5000/"
+)
+
+var (
+	cloneLong = `
+    Clone resources of a project to another project, in the current or another region
+
+    Resources exported by "arvan paas export" are read from the source project and applied to the
+    destination project, which should already exist. References to services and images of the
+    source project are rewritten to the destination project. Route hosts whose first label is named
+    after the source project, e.g "web-source.example.com", are rewritten too; other custom hosts are
+    dropped so a free domain is generated.
+
+    Use --include-data to copy contents of persistent volume claims using temporary pods. Volumes
+    attached to running pods may not be mountable by them; scale down their deployments first.`
+
+	podKind = bundleKind{"pods", "v1", "Pod", podsPath}
+)
+
+// NewCmdClone returns new cobra commad cloning resources of a project to another project.
+func NewCmdClone(o *options.Options) *cobra.Command {
+	var zoneName string
+	var includeSecrets, includeData bool
+	cmd := &cobra.Command{
+		Use:   "clone SOURCE DESTINATION",
+		Short: "Clone resources of a project to another project",
+		Long:  cloneLong,
+		Args:  cobra.ExactArgs(2),
+		Run: func(c *cobra.Command, args []string) {
+			source, destination := args[0], args[1]
+			sourceBase := getArvanPaasServerBase(o.Config)
+			destinationBase := sourceBase
+			destinationRegion := getCurrentRegion(o)
+			if len(zoneName) > 0 {
+				zone, err := getZoneByRegionName(o, zoneName)
+				o.CheckErr(err)
+				destinationBase = zonePaasServerBase(*zone)
+				destinationRegion = zoneFullName(*zone)
+			}
+			if source == destination && sourceBase == destinationBase {
+				o.CheckErr(utl.Errorf(utl.KindValidation, "source and destination projects are the same"))
+			}
+
+			err := paasRequest(o, http.MethodGet, destinationBase+fmt.Sprintf(projectPath, url.PathEscape(destination)), nil)
+			if utl.KindOf(err) == utl.KindNotFound {
+				o.CheckErr(utl.Errorf(utl.KindNotFound, "project %q not found in region %s. Create it by \"arvan paas new-project\" first", destination, destinationRegion))
+			}
+			o.CheckErr(err)
+
+			objects, err := getProjectObjects(o, sourceBase, source, exportOptions{includeSecrets: includeSecrets})
+			o.CheckErr(err)
+
+			count := 0
+			var claims []string
+			for i, kindObjects := range objects {
+				for _, obj := range kindObjects {
+					for _, warning := range rewriteClonedObject(obj, source, destination) {
+						fmt.Fprintf(o.ErrOut, "WARNING: %s\n", warning)
+					}
+					o.CheckErr(applyProjectObject(o, destinationBase, destination, bundleKinds[i], obj))
+					fmt.Fprintf(o.Out, "%s/%s cloned\n", bundleKinds[i].resource, objectName(obj))
+					count++
+					if bundleKinds[i].kind == "PersistentVolumeClaim" {
+						claims = append(claims, objectName(obj))
+					}
+				}
+			}
+
+			if includeData {
+				for _, claim := range claims {
+					fmt.Fprintf(o.Out, "Copying contents of persistent volume claim %q...\n", claim)
+					o.CheckErr(copyClaimData(o, claim, sourceBase, source, destinationBase, destination))
+				}
+			}
+
+			fmt.Fprintf(o.Out, "Cloned %d resources of project %q to project %q in region %s.\n", count, source, destination, destinationRegion)
+		},
+	}
+
+	cmd.Flags().StringVar(&zoneName, "zone", "", "Region of the destination project, the current region by default")
+	cmd.Flags().BoolVar(&includeSecrets, "include-secrets", false, "Clone secrets of the project")
+	cmd.Flags().BoolVar(&includeData, "include-data", false, "Copy contents of persistent volume claims")
+
+	return cmd
+}
+
+// rewriteClonedObject rewrites references of obj to source project so it refers to destination project.
+// It returns warnings about route hosts dropped.
+func rewriteClonedObject(obj object, source, destination string) []string {
+	rewriteReferences(obj, source, destination)
+
+	spec, _ := obj["spec"].(map[string]interface{})
+	host, _ := spec["host"].(string)
+	if obj["kind"] != "Route" || len(host) == 0 {
+		return nil
+	}
+	if rewritten, ok := rewriteRouteHost(host, source, destination); ok {
+		spec["host"] = rewritten
+		return nil
+	}
+	delete(spec, "host")
+	return []string{fmt.Sprintf("host %q of route %q is dropped, a free domain is generated instead", host, objectName(obj))}
+}
+
+// rewriteRouteHost rewrites the first label of host named after source project, either "source.suffix"
+// or "route-source.suffix" like hosts generated by the cluster, to destination. It returns false for other hosts.
+func rewriteRouteHost(host, source, destination string) (string, bool) {
+	i := strings.Index(host, ".")
+	if i <= 0 {
+		return "", false
+	}
+	label, suffix := host[:i], host[i:]
+	switch {
+	case label == source:
+		return destination + suffix, true
+	case strings.HasSuffix(label, "-"+source):
+		return strings.TrimSuffix(label, source) + destination + suffix, true
+	}
+	return "", false
+}
+
+// rewriteReferences rewrites namespace fields equal to source, and service hostnames e.g "db.source.svc"
+// or internal registry images e.g "image-registry.openshift-image-registry.svc:5000/source/app" in values.
+func rewriteReferences(value interface{}, source, destination string) {
+	replacer := strings.NewReplacer("."+source+".svc", "."+destination+".svc", internalRegistry+source+"/", internalRegistry+destination+"/")
+	var rewrite func(value interface{})
+	rewrite = func(value interface{}) {
+		switch v := value.(type) {
+		case object:
+			rewrite(map[string]interface{}(v))
+		case map[string]interface{}:
+			for key, field := range v {
+				s, ok := field.(string)
+				switch {
+				case ok && key == "namespace" && s == source:
+					v[key] = destination
+				case ok:
+					v[key] = replacer.Replace(s)
+				default:
+					rewrite(field)
+				}
+			}
+		case []interface{}:
+			for i, item := range v {
+				if s, ok := item.(string); ok {
+					v[i] = replacer.Replace(s)
+					continue
+				}
+				rewrite(item)
+			}
+		}
+	}
+	rewrite(value)
+}
+
+// applyProjectObject applies obj of kind k to project in paas api of serverBase, using server side apply.
+func applyProjectObject(o *options.Options, serverBase, project string, k bundleKind, obj object) error {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	endpoint := serverBase + fmt.Sprintf(k.path, url.PathEscape(project)) + "/" + url.PathEscape(objectName(obj)) +
+		"?fieldManager=" + fieldManager + "&force=true"
+	err = paasRequestWithBody(o, http.MethodPatch, endpoint, applyPatchContentType, body, nil)
+	if err != nil {
+		return fmt.Errorf("could not apply %s/%s: %w", k.resource, objectName(obj), err)
+	}
+	return nil
+}
+
+// copyClaimData copies contents of persistent volume claim of source project to the claim of the same name
+// in destination project, through a local temporary directory.
+func copyClaimData(o *options.Options, claim, sourceBase, source, destinationBase, destination string) error {
+	podName := dataCopyPodPrefix + claim
+	for _, p := range []struct{ base, project string }{{sourceBase, source}, {destinationBase, destination}} {
+		if err := applyProjectObject(o, p.base, p.project, podKind, dataCopyPod(podName, claim)); err != nil {
+			return err
+		}
+		p := p
+		defer func() {
+			if err := deleteProjectObject(o, p.base, p.project, podKind, podName); err != nil {
+				fmt.Fprintf(o.ErrOut, "WARNING: could not delete pod %s of project %s, delete it manually: %v\n", podName, p.project, err)
+			}
+		}()
+	}
+	for _, p := range []struct{ base, project string }{{sourceBase, source}, {destinationBase, destination}} {
+		if err := waitForPodRunning(o, p.base, p.project, podName); err != nil {
+			return err
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "arvan-clone-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	err = runPaasCommand(o, "rsync", "--server", sourceBase, "--namespace", source, podName+":"+dataCopyMountPath+"/", dir)
+	if err != nil {
+		return err
+	}
+	return runPaasCommand(o, "rsync", "--server", destinationBase, "--namespace", destination, dir+"/", podName+":"+dataCopyMountPath)
+}
+
+// dataCopyPod returns a pod named name mounting claim, idle until its contents are copied.
+func dataCopyPod(name, claim string) object {
+	return object{
+		"apiVersion": podKind.apiVersion,
+		"kind":       podKind.kind,
+		"metadata":   object{"name": name},
+		"spec": object{
+			"restartPolicy": "Never",
+			"containers": []object{{
+				"name":         "copy",
+				"image":        dataCopyImage,
+				"command":      []string{"sleep", fmt.Sprint(int(dataCopyTimeout.Seconds()) * 2)},
+				"volumeMounts": []object{{"name": "data", "mountPath": dataCopyMountPath}},
+			}},
+			"volumes": []object{{"name": "data", "persistentVolumeClaim": object{"claimName": claim}}},
+		},
+	}
+}
+
+// waitForPodRunning polls pod of project in paas api of serverBase until it's running.
+func waitForPodRunning(o *options.Options, serverBase, project, name string) error {
+	deadline := o.Clock.Now().Add(dataCopyTimeout)
+	endpoint := serverBase + fmt.Sprintf(podsPath, url.PathEscape(project)) + "/" + url.PathEscape(name)
+	for {
+		var pod struct {
+			Status struct {
+				Phase string `json:"phase"`
+			} `json:"status"`
+		}
+		if err := paasRequest(o, http.MethodGet, endpoint, &pod); err != nil {
+			return err
+		}
+		switch pod.Status.Phase {
+		case podPhaseRunning:
+			return nil
+		case podPhasePending, "":
+		default:
+			return utl.Errorf(utl.KindServer, "pod %q of project %q is %s", name, project, pod.Status.Phase)
+		}
+		if !o.Clock.Now().Before(deadline) {
+			return utl.Errorf(utl.KindServer, "timed out waiting for pod %q of project %q to run", name, project)
+		}
+		o.Clock.Sleep(interval * time.Second)
+	}
+}
+
+// deleteProjectObject deletes object of kind k named name from project in paas api of serverBase.
+func deleteProjectObject(o *options.Options, serverBase, project string, k bundleKind, name string) error {
+	endpoint := serverBase + fmt.Sprintf(k.path, url.PathEscape(project)) + "/" + url.PathEscape(name)
+	return paasRequest(o, http.MethodDelete, endpoint, nil)
+}
+
+// getZoneByRegionName returns active zone of region named either by its full name e.g "ir-thr-at1" or its zone name e.g "at1".
+func getZoneByRegionName(o *options.Options, name string) (*config.Zone, error) {
+	return getZoneByName(o, name[strings.LastIndex(name, "-")+1:])
+}
+
+// runPaasCommand runs "arvan paas" with args in a new process, e.g to use other server or namespace than
+// the ones already loaded by embedded oc commands of this process.
+func runPaasCommand(o *options.Options, args ...string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	command := exec.Command(executable, append([]string{"paas"}, args...)...)
+	command.Stdout = o.Out
+	command.Stderr = o.ErrOut
+	if err = command.Run(); err != nil {
+		return utl.Errorf(utl.KindServer, "arvan paas %s: %v", strings.Join(args, " "), err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package paas
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRewriteRouteHost(t *testing.T) {
+	tests := []struct {
+		host     string
+		expected string
+		ok       bool
+	}{
+		{host: "shop.example.com", expected: "shop-staging.example.com", ok: true},
+		{host: "web-shop.apps.example.com", expected: "web-shop-staging.apps.example.com", ok: true},
+		{host: "web.shop.example.com"},
+		{host: "shopping.example.com"},
+		{host: "webshop.example.com"},
+		{host: "example.com"},
+		{host: "shop"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.host, func(t *testing.T) {
+			actual, ok := rewriteRouteHost(test.host, "shop", "shop-staging")
+			if actual != test.expected || ok != test.ok {
+				t.Errorf("rewriteRouteHost(%q) = %q, %v, want %q, %v", test.host, actual, ok, test.expected, test.ok)
+			}
+		})
+	}
+}
+
+func TestRewriteClonedRoute(t *testing.T) {
+	route := object{
+		"kind":     "Route",
+		"metadata": map[string]interface{}{"name": "web"},
+		"spec":     map[string]interface{}{"host": "api.shop.io"},
+	}
+	warnings := rewriteClonedObject(route, "shop", "shop-staging")
+	if len(warnings) != 1 {
+		t.Errorf("warnings = %q, want a warning about the dropped host", warnings)
+	}
+	if host, ok := route["spec"].(map[string]interface{})["host"]; ok {
+		t.Errorf("host = %v, want custom host to be dropped", host)
+	}
+}
+
+func TestRewriteReferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected interface{}
+	}{
+		{
+			name:     "namespace field",
+			value:    object{"metadata": map[string]interface{}{"name": "web", "namespace": "shop"}},
+			expected: object{"metadata": map[string]interface{}{"name": "web", "namespace": "shop-staging"}},
+		},
+		{
+			name:     "namespace of another project",
+			value:    map[string]interface{}{"namespace": "shopping", "name": "shop"},
+			expected: map[string]interface{}{"namespace": "shopping", "name": "shop"},
+		},
+		{
+			name: "service host in env value",
+			value: map[string]interface{}{"env": []interface{}{
+				map[string]interface{}{"name": "DATABASE_URL", "value": "postgres://db.shop.svc:5432/app"},
+				map[string]interface{}{"name": "CACHE_HOST", "value": "redis.shop.svc.cluster.local"},
+				map[string]interface{}{"name": "API_HOST", "value": "api.shopping.svc"},
+			}},
+			expected: map[string]interface{}{"env": []interface{}{
+				map[string]interface{}{"name": "DATABASE_URL", "value": "postgres://db.shop-staging.svc:5432/app"},
+				map[string]interface{}{"name": "CACHE_HOST", "value": "redis.shop-staging.svc.cluster.local"},
+				map[string]interface{}{"name": "API_HOST", "value": "api.shopping.svc"},
+			}},
+		},
+		{
+			name: "registry images in lists",
+			value: map[string]interface{}{"containers": []interface{}{
+				map[string]interface{}{"name": "web", "image": internalRegistry + "shop/web:latest"},
+				map[string]interface{}{"name": "proxy", "image": "nginx:1.21"},
+			}},
+			expected: map[string]interface{}{"containers": []interface{}{
+				map[string]interface{}{"name": "web", "image": internalRegistry + "shop-staging/web:latest"},
+				map[string]interface{}{"name": "proxy", "image": "nginx:1.21"},
+			}},
+		},
+		{
+			name:     "strings in lists",
+			value:    map[string]interface{}{"args": []interface{}{"--upstream", "http://api.shop.svc:8080", internalRegistry + "shop/tools"}},
+			expected: map[string]interface{}{"args": []interface{}{"--upstream", "http://api.shop-staging.svc:8080", internalRegistry + "shop-staging/tools"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rewriteReferences(test.value, "shop", "shop-staging")
+			if !reflect.DeepEqual(test.value, test.expected) {
+				t.Errorf("rewritten value = %v, want %v", test.value, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,442 @@
+package paas
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/kubectl/pkg/scheme"
+
+	"github.com/arvancloud/cli/pkg/options"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+const (
+	defaultComposeFileName = "docker-compose.yml"
+	defaultVolumeSize      = "1Gi"
+	outputFormatYAML       = "yaml"
+)
+
+var importComposeLong = `
+    Import services of a docker-compose file as Arvan PaaS objects
+
+    Each service is converted to a Deployment, a Service for its first port, a Route if the port is
+    published, and PersistentVolumeClaims for its named volumes. Environment, replicas and resource
+    limits of services are kept, and services are created after services they depend on.
+
+    Services built from a local directory are converted to binary builds; run "arvan paas up DIR --name SERVICE"
+    to build them. Bind mounts and port ranges are not supported and skipped. With "-o json" objects are
+    printed as a List.`
+
+var (
+	// composeByteValue is a byte value of compose files e.g "100b", "512m" or "1gb"
+	composeByteValue = regexp.MustCompile(`^([0-9]+)(b|[kmg]b?)?$`)
+	// composeQuantity is a kubernetes binary quantity e.g "512Mi"
+	composeQuantity = regexp.MustCompile(`^[0-9]+(Ki|Mi|Gi|Ti)$`)
+)
+
+type composeFile struct {
+	Services map[string]composeService `yaml:"services"`
+}
+
+type composeService struct {
+	Image       string        `yaml:"image"`
+	Build       interface{}   `yaml:"build"`
+	Environment interface{}   `yaml:"environment"`
+	Ports       []interface{} `yaml:"ports"`
+	Volumes     []interface{} `yaml:"volumes"`
+	DependsOn   interface{}   `yaml:"depends_on"`
+	Deploy      struct {
+		Replicas  *int `yaml:"replicas"`
+		Resources struct {
+			Limits struct {
+				CPUs   string `yaml:"cpus"`
+				Memory string `yaml:"memory"`
+			} `yaml:"limits"`
+		} `yaml:"resources"`
+	} `yaml:"deploy"`
+}
+
+// NewCmdImport returns new cobra commad importing objects from other formats.
+func NewCmdImport(o *options.Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import applications from other formats",
+	}
+
+	cmd.AddCommand(newCmdImportCompose(o))
+
+	return cmd
+}
+
+// newCmdImportCompose returns new cobra commad converting a docker-compose file to paas objects.
+func newCmdImportCompose(o *options.Options) *cobra.Command {
+	var filename, name, volumeSize, output string
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "compose",
+		Short: "Import services of a docker-compose file",
+		Long:  importComposeLong,
+		Args:  cobra.NoArgs,
+		Run: func(c *cobra.Command, args []string) {
+			if output != "" && output != outputFormatYAML && output != outputFormatJSON {
+				o.CheckErr(utl.Errorf(utl.KindValidation, "invalid output format %q. One of: yaml, json", output))
+			}
+
+			if len(name) == 0 {
+				absPath, err := filepath.Abs(filename)
+				o.CheckErr(err)
+				name = appName(filepath.Base(filepath.Dir(absPath)))
+			}
+
+			manifest, warnings, err := loadCompose(filename, name, volumeSize)
+			o.CheckErr(err)
+			for _, warning := range warnings {
+				fmt.Fprintf(o.ErrOut, "WARNING: %s\n", warning)
+			}
+
+			objects, err := manifest.render()
+			o.CheckErr(err)
+
+			if dryRun || output != "" {
+				if output == "" {
+					output = outputFormatYAML
+				}
+				o.CheckErr(printObjects(o.Out, objects, output))
+				if dryRun {
+					return
+				}
+			}
+
+			data, err := marshalObjects(objects)
+			o.CheckErr(err)
+			o.CheckErr(applyObjects(c.Parent().Parent(), data))
+		},
+	}
+
+	cmd.Flags().StringVarP(&filename, "filename", "f", defaultComposeFileName, "docker-compose file to import")
+	cmd.Flags().StringVar(&name, "name", "", "Name of the application, name of directory of the compose file by default")
+	cmd.Flags().StringVar(&volumeSize, "volume-size", defaultVolumeSize, "Size of persistent volume claims of named volumes")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print converted objects without creating them")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format of converted objects. One of: yaml, json")
+
+	return cmd
+}
+
+// loadCompose reads docker-compose file in path as a Manifest named name. It returns warnings about
+// parts of the file which could not be converted.
+func loadCompose(path, name, volumeSize string) (*Manifest, []string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, utl.NewError(utl.KindValidation, err)
+	}
+	var compose composeFile
+	if err = yaml.Unmarshal(data, &compose); err != nil {
+		return nil, nil, utl.Errorf(utl.KindValidation, "invalid compose file %s: %v", path, err)
+	}
+	if len(compose.Services) == 0 {
+		return nil, nil, utl.Errorf(utl.KindValidation, "no services declared in %s", path)
+	}
+
+	manifest := &Manifest{ApiVersion: manifestApiVersion, Name: name}
+	composeNames := make([]string, 0, len(compose.Services))
+	for composeName := range compose.Services {
+		composeNames = append(composeNames, composeName)
+	}
+	sort.Strings(composeNames)
+
+	var warnings []string
+	services := map[string]ManifestService{}
+	convertedFrom := map[string]string{}
+	for _, composeName := range composeNames {
+		s, serviceWarnings, err := convertComposeService(composeName, compose.Services[composeName], volumeSize)
+		if err != nil {
+			return nil, nil, utl.Errorf(utl.KindValidation, "invalid service %q in %s: %v", composeName, path, err)
+		}
+		if other, ok := convertedFrom[s.Name]; ok {
+			return nil, nil, utl.Errorf(utl.KindValidation, "services %q and %q in %s are both converted to %q. Rename one of them",
+				other, composeName, path, s.Name)
+		}
+		convertedFrom[s.Name] = composeName
+		services[s.Name] = s
+		warnings = append(warnings, serviceWarnings...)
+	}
+
+	ordered, err := orderByDependencies(services)
+	if err != nil {
+		return nil, nil, utl.NewError(utl.KindValidation, err)
+	}
+	manifest.Services = ordered
+
+	if err = manifest.validate(); err != nil {
+		return nil, nil, utl.Errorf(utl.KindValidation, "could not convert %s: %v", path, err)
+	}
+	sort.Strings(warnings)
+	return manifest, warnings, nil
+}
+
+func convertComposeService(composeName string, cs composeService, volumeSize string) (ManifestService, []string, error) {
+	var warnings []string
+	s := ManifestService{
+		Name:     appName(composeName),
+		Image:    cs.Image,
+		Replicas: cs.Deploy.Replicas,
+		Resources: ManifestResources{
+			CPU: cs.Deploy.Resources.Limits.CPUs,
+		},
+	}
+
+	memory, err := composeMemory(cs.Deploy.Resources.Limits.Memory)
+	if err != nil {
+		return s, nil, err
+	}
+	s.Resources.Memory = memory
+
+	if len(s.Image) == 0 && cs.Build != nil {
+		s.Build = &ManifestBuild{Binary: true}
+		warnings = append(warnings, fmt.Sprintf("service %q is built from a local directory. Run \"arvan paas up DIR --name %s\" to build it", composeName, s.Name))
+	}
+
+	env, err := composeEnvironment(cs.Environment)
+	if err != nil {
+		return s, nil, err
+	}
+	s.Env = env
+
+	for _, p := range cs.Ports {
+		if composePortRange(p) {
+			warnings = append(warnings, fmt.Sprintf("service %q: port range %v is not supported and skipped", composeName, p))
+			continue
+		}
+		port, published, err := composePort(p)
+		if err != nil {
+			return s, nil, err
+		}
+		if s.Port > 0 {
+			warnings = append(warnings, fmt.Sprintf("service %q: only the first port is exposed, port %d is skipped", composeName, port))
+			continue
+		}
+		s.Port = port
+		if published {
+			s.Routes = []ManifestRoute{{}}
+		}
+	}
+
+	for _, v := range cs.Volumes {
+		volume, ok := composeVolume(v, volumeSize)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("service %q: bind mount %v is skipped", composeName, v))
+			continue
+		}
+		s.Volumes = append(s.Volumes, volume)
+	}
+
+	dependsOn, err := composeDependsOn(cs.DependsOn)
+	if err != nil {
+		return s, nil, err
+	}
+	for _, dependency := range dependsOn {
+		s.DependsOn = append(s.DependsOn, appName(dependency))
+	}
+
+	return s, warnings, nil
+}
+
+// composeEnvironment converts environment either as a map or a list of NAME=value.
+func composeEnvironment(environment interface{}) (map[string]string, error) {
+	env := map[string]string{}
+	switch e := environment.(type) {
+	case nil:
+	case map[interface{}]interface{}:
+		for name, value := range e {
+			if value == nil {
+				value = ""
+			}
+			env[fmt.Sprint(name)] = fmt.Sprint(value)
+		}
+	case []interface{}:
+		for _, item := range e {
+			parts := strings.SplitN(fmt.Sprint(item), "=", 2)
+			if len(parts) == 1 {
+				parts = append(parts, "")
+			}
+			env[parts[0]] = parts[1]
+		}
+	default:
+		return nil, fmt.Errorf("invalid environment %v", environment)
+	}
+	return env, nil
+}
+
+// composePort returns container port of a port either in short syntax e.g "8080:80" or long syntax,
+// and whether it's published on host.
+func composePort(port interface{}) (int, bool, error) {
+	switch p := port.(type) {
+	case int:
+		return p, false, nil
+	case string:
+		parts := strings.Split(strings.SplitN(p, "/", 2)[0], ":")
+		containerPort, err := strconv.Atoi(parts[len(parts)-1])
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid port %q", p)
+		}
+		return containerPort, len(parts) > 1, nil
+	case map[interface{}]interface{}:
+		containerPort, err := strconv.Atoi(fmt.Sprint(p["target"]))
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid port %v", p)
+		}
+		return containerPort, p["published"] != nil, nil
+	}
+	return 0, false, fmt.Errorf("invalid port %v", port)
+}
+
+// composePortRange reports whether port in short syntax maps a range of ports e.g "8000-8010:8000-8010".
+func composePortRange(port interface{}) bool {
+	p, ok := port.(string)
+	if !ok {
+		return false
+	}
+	parts := strings.Split(strings.SplitN(p, "/", 2)[0], ":")
+	return strings.Contains(parts[len(parts)-1], "-")
+}
+
+// composeVolume converts a named volume e.g "data:/var/lib/data". It returns false for bind mounts.
+func composeVolume(volume interface{}, size string) (ManifestVolume, bool) {
+	var source, target string
+	switch v := volume.(type) {
+	case string:
+		parts := strings.Split(v, ":")
+		if len(parts) < 2 {
+			return ManifestVolume{}, false
+		}
+		source, target = parts[0], parts[1]
+	case map[interface{}]interface{}:
+		if fmt.Sprint(v["type"]) != "volume" {
+			return ManifestVolume{}, false
+		}
+		source, target = fmt.Sprint(v["source"]), fmt.Sprint(v["target"])
+	default:
+		return ManifestVolume{}, false
+	}
+	if strings.ContainsAny(source, "/.~") {
+		return ManifestVolume{}, false
+	}
+	return ManifestVolume{Name: appName(source), MountPath: target, Size: size}, true
+}
+
+// composeDependsOn returns services depended on either as a list or a map of conditions.
+func composeDependsOn(dependsOn interface{}) ([]string, error) {
+	var dependencies []string
+	switch d := dependsOn.(type) {
+	case nil:
+	case []interface{}:
+		for _, item := range d {
+			dependencies = append(dependencies, fmt.Sprint(item))
+		}
+	case map[interface{}]interface{}:
+		for name := range d {
+			dependencies = append(dependencies, fmt.Sprint(name))
+		}
+	default:
+		return nil, fmt.Errorf("invalid depends_on %v", dependsOn)
+	}
+	sort.Strings(dependencies)
+	return dependencies, nil
+}
+
+// composeMemory converts memory in compose format e.g "512m" or "100b" to kubernetes quantity e.g "512Mi" or "100".
+// Kubernetes binary quantities e.g "1Gi" are kept.
+func composeMemory(memory string) (string, error) {
+	if len(memory) == 0 || composeQuantity.MatchString(memory) {
+		return memory, nil
+	}
+	match := composeByteValue.FindStringSubmatch(strings.ToLower(memory))
+	if match == nil {
+		return "", fmt.Errorf("invalid memory %q, want a byte value e.g 512m", memory)
+	}
+	suffixes := map[string]string{"": "", "k": "Ki", "m": "Mi", "g": "Gi"}
+	return match[1] + suffixes[strings.TrimSuffix(match[2], "b")], nil
+}
+
+// orderByDependencies returns services sorted by name, each after services it depends on.
+func orderByDependencies(services map[string]ManifestService) ([]ManifestService, error) {
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var ordered []ManifestService
+	visited := map[string]bool{}
+	visiting := map[string]bool{}
+	var visit func(name string) error
+	visit = func(name string) error {
+		if visited[name] {
+			return nil
+		}
+		if visiting[name] {
+			return fmt.Errorf("circular dependency of service %q", name)
+		}
+		visiting[name] = true
+		for _, dependency := range services[name].DependsOn {
+			if _, ok := services[dependency]; !ok {
+				return fmt.Errorf("service %q depends on undeclared service %q", name, dependency)
+			}
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		visiting[name] = false
+		visited[name] = true
+		ordered = append(ordered, services[name])
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// printObjects decodes objects using types registered in the paas scheme and prints them in output format,
+// as a multi-document yaml or a List in json.
+func printObjects(out io.Writer, objects []object, output string) error {
+	decoder := scheme.Codecs.UniversalDeserializer()
+	list := &corev1.List{TypeMeta: metav1.TypeMeta{Kind: "List", APIVersion: "v1"}}
+	for _, o := range objects {
+		data, err := json.Marshal(o)
+		if err != nil {
+			return err
+		}
+		decoded, _, err := decoder.Decode(data, nil, nil)
+		if err != nil {
+			return err
+		}
+		list.Items = append(list.Items, runtime.RawExtension{Object: decoded})
+	}
+
+	if output == outputFormatJSON {
+		return (&printers.JSONPrinter{}).PrintObj(list, out)
+	}
+	printer := &printers.YAMLPrinter{}
+	for _, item := range list.Items {
+		if err := printer.PrintObj(item.Object, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package paas
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeCompose writes content to a docker-compose file in a temporary directory and returns its path.
+func writeCompose(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), defaultComposeFileName)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadCompose(t *testing.T) {
+	path := writeCompose(t, `
+version: "3.8"
+services:
+  web:
+    build: .
+    ports:
+      - "8080:80"
+      - "8443:443"
+    environment:
+      - DEBUG=1
+      - EMPTY
+    depends_on:
+      - db
+      - Cache_Store
+  db:
+    image: postgres:13
+    environment:
+      POSTGRES_DB: shop
+    volumes:
+      - pgdata:/var/lib/postgresql/data
+      - ./init:/docker-entrypoint-initdb.d
+    deploy:
+      resources:
+        limits:
+          cpus: "0.5"
+          memory: 512m
+  Cache_Store:
+    image: redis:6
+    ports:
+      - target: 6379
+    deploy:
+      replicas: 2
+`)
+
+	manifest, warnings, err := loadCompose(path, "shop", "2Gi")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replicas := 2
+	expected := []ManifestService{
+		{
+			Name:     "cache-store",
+			Image:    "redis:6",
+			Replicas: &replicas,
+			Port:     6379,
+			Env:      map[string]string{},
+		},
+		{
+			Name:      "db",
+			Image:     "postgres:13",
+			Env:       map[string]string{"POSTGRES_DB": "shop"},
+			Resources: ManifestResources{CPU: "0.5", Memory: "512Mi"},
+			Volumes:   []ManifestVolume{{Name: "pgdata", MountPath: "/var/lib/postgresql/data", Size: "2Gi"}},
+		},
+		{
+			Name:      "web",
+			Build:     &ManifestBuild{Binary: true},
+			Port:      80,
+			Routes:    []ManifestRoute{{}},
+			Env:       map[string]string{"DEBUG": "1", "EMPTY": ""},
+			DependsOn: []string{"cache-store", "db"},
+		},
+	}
+	if !reflect.DeepEqual(manifest.Services, expected) {
+		t.Errorf("services = %+v, want %+v", manifest.Services, expected)
+	}
+
+	expectedWarnings := []string{
+		`service "db": bind mount ./init:/docker-entrypoint-initdb.d is skipped`,
+		`service "web" is built from a local directory. Run "arvan paas up DIR --name web" to build it`,
+		`service "web": only the first port is exposed, port 443 is skipped`,
+	}
+	if !reflect.DeepEqual(warnings, expectedWarnings) {
+		t.Errorf("warnings = %q, want %q", warnings, expectedWarnings)
+	}
+}
+
+func TestLoadComposePortRange(t *testing.T) {
+	path := writeCompose(t, `
+services:
+  web:
+    image: nginx
+    ports:
+      - "9000-9010:9000-9010"
+      - "8080:80/tcp"
+`)
+
+	manifest, warnings, err := loadCompose(path, "shop", defaultVolumeSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := manifest.Services[0]; s.Port != 80 || len(s.Routes) != 1 {
+		t.Errorf("port = %d with %d routes, want published port 80", s.Port, len(s.Routes))
+	}
+	expectedWarnings := []string{`service "web": port range 9000-9010:9000-9010 is not supported and skipped`}
+	if !reflect.DeepEqual(warnings, expectedWarnings) {
+		t.Errorf("warnings = %q, want %q", warnings, expectedWarnings)
+	}
+}
+
+func TestLoadComposeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{
+			name:    "no services",
+			content: "version: \"3\"\n",
+			err:     "no services declared",
+		},
+		{
+			name:    "services converted to the same name",
+			content: "services:\n  api_v1:\n    image: a\n  api-v1:\n    image: b\n",
+			err:     `services "api-v1" and "api_v1"`,
+		},
+		{
+			name:    "undeclared dependency",
+			content: "services:\n  web:\n    image: nginx\n    depends_on: [db]\n",
+			err:     `depends on undeclared service "db"`,
+		},
+		{
+			name:    "circular dependency",
+			content: "services:\n  a:\n    image: a\n    depends_on: [b]\n  b:\n    image: b\n    depends_on: [a]\n",
+			err:     "circular dependency",
+		},
+		{
+			name:    "invalid port",
+			content: "services:\n  web:\n    image: nginx\n    ports: [\"http\"]\n",
+			err:     `invalid port "http"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := loadCompose(writeCompose(t, test.content), "shop", defaultVolumeSize)
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("error = %v, want error containing %q", err, test.err)
+			}
+		})
+	}
+}
+
+func TestComposeMemory(t *testing.T) {
+	tests := map[string]string{
+		"":     "",
+		"100":  "100",
+		"100b": "100",
+		"100B": "100",
+		"512m": "512Mi",
+		"512M": "512Mi",
+		"1gb":  "1Gi",
+		"64k":  "64Ki",
+		"64kb": "64Ki",
+		"1Gi":  "1Gi",
+	}
+	for memory, expected := range tests {
+		actual, err := composeMemory(memory)
+		if err != nil {
+			t.Errorf("composeMemory(%q) failed: %v", memory, err)
+		} else if actual != expected {
+			t.Errorf("composeMemory(%q) = %q, want %q", memory, actual, expected)
+		}
+	}
+}
+
+func TestComposeMemoryInvalid(t *testing.T) {
+	for _, memory := range []string{"1.5g", "512x", "m", "100bb", "1t"} {
+		if actual, err := composeMemory(memory); err == nil {
+			t.Errorf("composeMemory(%q) = %q, want an error", memory, actual)
+		}
+	}
+}
+
+func TestPrintObjectsJSON(t *testing.T) {
+	manifest := Manifest{
+		ApiVersion: manifestApiVersion,
+		Name:       "shop",
+		Services:   []ManifestService{{Name: "web", Image: "nginx", Port: 80, Routes: []ManifestRoute{{}}}},
+	}
+	objects, err := manifest.render()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	if err = printObjects(out, objects, outputFormatJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	var list struct {
+		ApiVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Items      []struct {
+			Kind string `json:"kind"`
+		} `json:"items"`
+	}
+	if err = json.Unmarshal(out.Bytes(), &list); err != nil {
+		t.Fatalf("output is not a json document: %v\n%s", err, out)
+	}
+	if list.ApiVersion != "v1" || list.Kind != "List" {
+		t.Errorf("output is %s %s, want v1 List", list.ApiVersion, list.Kind)
+	}
+	var kinds []string
+	for _, item := range list.Items {
+		kinds = append(kinds, item.Kind)
+	}
+	if expected := []string{"Deployment", "Service", "Route"}; !reflect.DeepEqual(kinds, expected) {
+		t.Errorf("items = %v, want %v", kinds, expected)
+	}
+}
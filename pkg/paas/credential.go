@@ -0,0 +1,83 @@
+package paas
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arvancloud/cli/pkg/config"
+	"github.com/arvancloud/cli/pkg/options"
+)
+
+const (
+	execCredentialApiVersion = "client.authentication.k8s.io/v1beta1"
+	execCredentialKind       = "ExecCredential"
+)
+
+// ExecCredential is printed by "arvan paas credential" for kubectl exec credential plugins.
+type ExecCredential struct {
+	Kind       string               `json:"kind"`
+	ApiVersion string               `json:"apiVersion"`
+	Status     ExecCredentialStatus `json:"status"`
+}
+
+// ExecCredentialStatus holds the token kubectl authorizes with.
+type ExecCredentialStatus struct {
+	Token               string     `json:"token"`
+	ExpirationTimestamp *time.Time `json:"expirationTimestamp,omitempty"`
+}
+
+// NewCmdCredential returns new cobra commad printing credentials of the active profile as an ExecCredential.
+// It's hidden since it's only run by kubectl using kubeconfig users generated by "arvan login --kubeconfig-auth exec".
+func NewCmdCredential(o *options.Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "credential",
+		Short:  "Print credentials for kubectl exec credential plugin",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		// skip syncing kubeconfig and checking updates of paas commands, stdout is read by kubectl
+		PersistentPreRun: func(c *cobra.Command, args []string) {},
+		Run: func(c *cobra.Command, args []string) {
+			o.CheckErr(requireCredentials(o))
+			o.CheckErr(printJSON(o.Out, getExecCredential(o.Config)))
+		},
+	}
+
+	return cmd
+}
+
+// getExecCredential returns ExecCredential holding the token of arvanConfig.
+func getExecCredential(arvanConfig *config.ConfigInfo) ExecCredential {
+	credential := ExecCredential{
+		Kind:       execCredentialKind,
+		ApiVersion: execCredentialApiVersion,
+		Status: ExecCredentialStatus{
+			Token: arvanConfig.GetToken(),
+		},
+	}
+	if expiry := arvanConfig.GetTokenExpiry(); !expiry.IsZero() {
+		credential.Status.ExpirationTimestamp = &expiry
+	}
+	return credential
+}
+
+// kubeConfigUserInfo returns credentials of kubeconfig users, either the token itself or
+// an exec config running "arvan paas credential" depending on kubeconfig auth of arvanConfig.
+func kubeConfigUserInfo(arvanConfig *config.ConfigInfo) (UserInfo, error) {
+	if arvanConfig.GetKubeConfigAuth() != config.KubeConfigAuthExec {
+		return UserInfo{Token: arvanConfig.GetToken()}, nil
+	}
+
+	command, err := os.Executable()
+	if err != nil {
+		return UserInfo{}, err
+	}
+	return UserInfo{
+		Exec: &ExecConfig{
+			ApiVersion: execCredentialApiVersion,
+			Command:    command,
+			Args:       []string{"paas", "credential"},
+		},
+	}, nil
+}
@@ -0,0 +1,98 @@
+package paas_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/arvancloud/cli/pkg/api/apitest"
+	"github.com/arvancloud/cli/pkg/cli/clitest"
+	"github.com/arvancloud/cli/pkg/paas"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+// runCredential runs "arvan paas credential" and parses the ExecCredential it prints.
+func runCredential(t *testing.T, h *clitest.Harness) paas.ExecCredential {
+	t.Helper()
+	result := h.Run("", "paas", "credential")
+	if result.ExitCode != 0 {
+		t.Fatalf("credential failed:\n%s", result)
+	}
+	if len(result.Stderr) > 0 {
+		t.Errorf("credential wrote to stderr:\n%s", result)
+	}
+
+	var credential paas.ExecCredential
+	if err := json.Unmarshal([]byte(result.Stdout), &credential); err != nil {
+		t.Fatalf("output is not an ExecCredential: %v\n%s", err, result)
+	}
+	if credential.Kind != "ExecCredential" || credential.ApiVersion != "client.authentication.k8s.io/v1beta1" {
+		t.Errorf("credential is %s %s, want client.authentication.k8s.io/v1beta1 ExecCredential", credential.ApiVersion, credential.Kind)
+	}
+	return credential
+}
+
+func TestCredentialApiKey(t *testing.T) {
+	h := clitest.New(t)
+
+	credential := runCredential(t, h)
+	if credential.Status.Token != apitest.DefaultApiKey {
+		t.Errorf("token = %q, want the api key", credential.Status.Token)
+	}
+	if credential.Status.ExpirationTimestamp != nil {
+		t.Errorf("expiration of an api key = %v, want none", credential.Status.ExpirationTimestamp)
+	}
+}
+
+func TestCredentialRefreshesBearerToken(t *testing.T) {
+	h := clitest.New(t)
+	loginBearer(t, h)
+	h.Server.BearerToken = "token-2"
+	h.Server.RefreshToken = "refresh-1"
+	h.Server.TokenExpiresIn = 3600
+
+	credential := runCredential(t, h)
+	if credential.Status.Token != "token-2" {
+		t.Errorf("token = %q, want refreshed token-2", credential.Status.Token)
+	}
+	if expiry := credential.Status.ExpirationTimestamp; expiry == nil || !expiry.After(time.Now()) {
+		t.Errorf("expiration = %v, want expiry of the refreshed token", expiry)
+	}
+}
+
+func TestCredentialLoggedOut(t *testing.T) {
+	h := clitest.New(t)
+	if result := h.Run("", "logout"); result.ExitCode != 0 {
+		t.Fatalf("logout failed:\n%s", result)
+	}
+
+	result := h.Run("", "paas", "credential")
+	if result.ExitCode != utl.AuthErrorExitCode {
+		t.Errorf("exit code = %d, want %d:\n%s", result.ExitCode, utl.AuthErrorExitCode, result)
+	}
+	if len(result.Stdout) > 0 {
+		t.Errorf("credential is printed while logged out:\n%s", result)
+	}
+}
+
+func TestLoginKubeConfigAuthExec(t *testing.T) {
+	h := clitest.New(t)
+	h.Server.Projects["at1"] = []string{"web"}
+
+	result := h.Run(apitest.DefaultApiKey+"\n", "login", "--kubeconfig-auth", "exec")
+	if result.ExitCode != 0 {
+		t.Fatalf("login failed:\n%s", result)
+	}
+
+	users := loadKubeConfig(t, h).Users
+	if len(users) != 1 {
+		t.Fatalf("users = %+v, want a single user", users)
+	}
+	user := users[0].User
+	if len(user.Token) > 0 || user.Exec == nil {
+		t.Fatalf("user = %+v, want an exec config instead of a token", user)
+	}
+	if user.Exec.ApiVersion != "client.authentication.k8s.io/v1beta1" || len(user.Exec.Args) != 2 || user.Exec.Args[0] != "paas" || user.Exec.Args[1] != "credential" {
+		t.Errorf("exec config = %+v, want arvan paas credential", user.Exec)
+	}
+}
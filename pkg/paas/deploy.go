@@ -0,0 +1,151 @@
+package paas
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arvancloud/cli/pkg/options"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+var (
+	deployLong = `
+    Deploy an application declared in arvan.yaml
+
+    The manifest declares services of the application, each built from a git repository or run
+    from an image, along with its env, resources, routes, volumes and replicas:
+
+        apiVersion: v1
+        name: shop
+        services:
+          - name: web
+            build:
+              git: https://github.com/example/shop.git
+            port: 8080
+            replicas: 2
+            env:
+              MODE: production
+            resources:
+              cpu: 500m
+              memory: 512Mi
+            routes:
+              - host: shop.example.com
+                tls: true
+            volumes:
+              - name: uploads
+                mountPath: /app/uploads
+                size: 1Gi
+
+    Objects are labeled by name of the application, and objects of the application removed from
+    the manifest are pruned. Use --diff to preview changes computed by the server without applying them.`
+)
+
+// pruneKinds are kinds of objects rendered from manifests, which are pruned when removed from the manifest
+var pruneKinds = []string{
+	"core/v1/Service",
+	"core/v1/PersistentVolumeClaim",
+	"apps/v1/Deployment",
+	"route.openshift.io/v1/Route",
+	"build.openshift.io/v1/BuildConfig",
+	"image.openshift.io/v1/ImageStream",
+}
+
+// NewCmdDeploy returns new cobra commad deploying an application declared in arvan.yaml.
+func NewCmdDeploy(o *options.Options) *cobra.Command {
+	var filename string
+	var diff, dryRun bool
+	cmd := &cobra.Command{
+		Use:   "deploy",
+		Short: "Deploy an application declared in arvan.yaml",
+		Long:  deployLong,
+		Args:  cobra.NoArgs,
+		Run: func(c *cobra.Command, args []string) {
+			manifest, err := loadManifest(filename)
+			o.CheckErr(err)
+
+			objects, err := manifest.render()
+			o.CheckErr(err)
+			data, err := marshalObjects(objects)
+			o.CheckErr(err)
+
+			if dryRun {
+				_, err = o.Out.Write(data)
+				o.CheckErr(err)
+				return
+			}
+
+			if len(manifest.Project) > 0 {
+				o.CheckErr(c.Flags().Set("namespace", manifest.Project))
+			}
+
+			if diff {
+				o.CheckErr(withObjectsFile(data, func(path string) error {
+					return runSubcommand(c.Parent(), "diff", "-f", path, "--server-side")
+				}))
+				return
+			}
+
+			pruneArgs := []string{"--prune", "-l", appLabel + "=" + manifest.Name}
+			for _, kind := range pruneKinds {
+				pruneArgs = append(pruneArgs, "--prune-whitelist", kind)
+			}
+			o.CheckErr(applyObjects(c.Parent(), data, pruneArgs...))
+			fmt.Fprintf(o.Out, "Application %q deployed.\n", manifest.Name)
+		},
+	}
+
+	cmd.Flags().StringVarP(&filename, "filename", "f", defaultManifestFileName, "Manifest declaring the application")
+	cmd.Flags().BoolVar(&diff, "diff", false, "Show changes computed by the server without applying them")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print objects rendered from the manifest without applying them")
+
+	return cmd
+}
+
+// applyObjects applies objects in yaml data using apply subcommand of paasCommand with args.
+func applyObjects(paasCommand *cobra.Command, data []byte, args ...string) error {
+	return withObjectsFile(data, func(path string) error {
+		return runSubcommand(paasCommand, append([]string{"apply", "-f", path}, args...)...)
+	})
+}
+
+// withObjectsFile writes objects in yaml data to a temporary file and calls f with its path.
+func withObjectsFile(data []byte, f func(path string) error) error {
+	file, err := ioutil.TempFile("", "arvan-objects-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return f(file.Name())
+}
+
+// runSubcommand runs subcommand of paasCommand found by args, e.g "rollout status deployment/web", as if they
+// were given in command line. Subcommand shares persistent flags of paasCommand e.g kubeconfig and namespace already parsed.
+func runSubcommand(paasCommand *cobra.Command, args ...string) error {
+	subcommand, flags, err := paasCommand.Find(args)
+	if err != nil || subcommand == paasCommand {
+		return fmt.Errorf("command %q not found", args[0])
+	}
+
+	if err = subcommand.ParseFlags(flags); err != nil {
+		return utl.NewError(utl.KindValidation, err)
+	}
+	switch {
+	case subcommand.Run != nil:
+		subcommand.Run(subcommand, subcommand.Flags().Args())
+		return nil
+	case subcommand.RunE != nil:
+		return subcommand.RunE(subcommand, subcommand.Flags().Args())
+	}
+	return fmt.Errorf("command %q is not runnable", subcommand.CommandPath())
+}
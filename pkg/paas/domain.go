@@ -0,0 +1,372 @@
+package paas
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arvancloud/cli/pkg/options"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+const (
+	domainLabel          = "arvan.ir/domain"
+	dnsLookupTimeout     = 10 * time.Second
+	domainStatusOK       = "ok"
+	domainStatusNotFound = "not found"
+	domainStatusWrong    = "misconfigured"
+)
+
+var domainLong = `
+    Manage domains of routes of the current project
+
+    Each route is either served on a free domain generated by Arvan, or on a custom domain which
+    should point to the gateway of the region by a CNAME record. Use "verify" to check DNS records
+    of custom domains.`
+
+// domainResolver looks up DNS records of domains. It's satisfied by net.Resolver.
+type domainResolver interface {
+	LookupCNAME(ctx context.Context, host string) (string, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// resolver checks DNS records of custom domains. It can be replaced to stub DNS lookups.
+var resolver domainResolver = net.DefaultResolver
+
+type routeList struct {
+	Items []struct {
+		Metadata struct {
+			Name        string            `json:"name"`
+			Annotations map[string]string `json:"annotations"`
+		} `json:"metadata"`
+		Spec struct {
+			Host string `json:"host"`
+			To   struct {
+				Name string `json:"name"`
+			} `json:"to"`
+		} `json:"spec"`
+		Status struct {
+			Ingress []struct {
+				RouterCanonicalHostname string `json:"routerCanonicalHostname"`
+			} `json:"ingress"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// DomainVerification is the result of checking DNS records of a custom domain.
+type DomainVerification struct {
+	Host       string
+	Gateway    string
+	ResolvesTo string
+	Status     string
+}
+
+// NewCmdDomain returns new cobra commad managing free and custom domains of routes.
+func NewCmdDomain(o *options.Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "domain",
+		Aliases: []string{"domains"},
+		Short:   "Manage free and custom domains of routes",
+		Long:    domainLong,
+	}
+
+	cmd.AddCommand(newCmdDomainList(o))
+	cmd.AddCommand(newCmdDomainAdd(o))
+	cmd.AddCommand(newCmdDomainRemove(o))
+	cmd.AddCommand(newCmdDomainVerify(o))
+
+	return cmd
+}
+
+// newCmdDomainList returns new cobra commad listing domains of routes of current project.
+func newCmdDomainList(o *options.Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List domains of routes and the gateway custom domains should point to",
+		Args:  cobra.NoArgs,
+		Run: func(c *cobra.Command, args []string) {
+			namespace, err := commandNamespace(c)
+			o.CheckErr(err)
+			info, err := getProjectDomains(o, namespace)
+			o.CheckErr(err)
+			sprintDomains(o.Out, *info)
+		},
+	}
+
+	return cmd
+}
+
+// newCmdDomainAdd returns new cobra commad serving a service or a route on a custom domain.
+func newCmdDomainAdd(o *options.Options) *cobra.Command {
+	var service, route string
+	var tls bool
+	cmd := &cobra.Command{
+		Use:   "add DOMAIN",
+		Short: "Serve a service or an existing route on a custom domain",
+		Example: `  # Create a route serving service web on shop.example.com
+  arvan paas domain add shop.example.com --service web --tls
+
+  # Serve route web on shop.example.com instead of its current domain
+  arvan paas domain add shop.example.com --route web`,
+		Args: cobra.ExactArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			host := strings.ToLower(strings.TrimSuffix(args[0], "."))
+			if (len(service) == 0) == (len(route) == 0) {
+				o.CheckErr(utl.Errorf(utl.KindValidation, "either --service or --route is required"))
+			}
+			namespace, err := commandNamespace(c)
+			o.CheckErr(err)
+
+			serverBase := getArvanPaasServerBase(o.Config)
+			routeKind, err := getBundleKind("Route")
+			o.CheckErr(err)
+			if len(route) > 0 {
+				obj, err := getProjectObject(o, serverBase, namespace, routeKind, route)
+				o.CheckErr(err)
+				spec, _ := obj["spec"].(map[string]interface{})
+				spec["host"] = host
+				metadata, _ := obj["metadata"].(map[string]interface{})
+				if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+					delete(annotations, hostGeneratedAnnotation)
+				}
+				o.CheckErr(putProjectObject(o, serverBase, namespace, routeKind, obj))
+			} else {
+				route = appName(host)
+				spec := object{
+					"host": host,
+					"to":   object{"kind": "Service", "name": service},
+				}
+				if tls {
+					spec["tls"] = object{"termination": "edge", "insecureEdgeTerminationPolicy": "Redirect"}
+				}
+				o.CheckErr(createProjectObject(o, serverBase, namespace, routeKind, object{
+					"apiVersion": routeKind.apiVersion,
+					"kind":       routeKind.kind,
+					"metadata":   object{"name": route, "labels": object{domainLabel: "true"}},
+					"spec":       spec,
+				}))
+			}
+
+			fmt.Fprintf(o.Out, "Route %q serves %s.\n", route, host)
+			if info, err := getProjectDomains(o, namespace); err == nil && len(info.Gateway) > 0 {
+				fmt.Fprintf(o.Out, "Point %s to %s by a CNAME record, then run \"arvan paas domain verify %s\".\n", host, info.Gateway, host)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&service, "service", "", "Service to create a route for")
+	cmd.Flags().StringVar(&route, "route", "", "Existing route to serve on the domain")
+	cmd.Flags().BoolVar(&tls, "tls", false, "Serve the new route over https, redirecting http")
+
+	return cmd
+}
+
+// newCmdDomainRemove returns new cobra commad removing a custom domain from the route serving it.
+func newCmdDomainRemove(o *options.Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove DOMAIN",
+		Short: "Remove a custom domain from the route serving it",
+		Long: `
+    Remove a custom domain from the route serving it
+
+    Routes created by "arvan paas domain add --service" are deleted. Other routes are kept, and a free
+    domain is generated for them instead.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			host := strings.ToLower(strings.TrimSuffix(args[0], "."))
+			namespace, err := commandNamespace(c)
+			o.CheckErr(err)
+			info, err := getProjectDomains(o, namespace)
+			o.CheckErr(err)
+
+			for _, domain := range info.Domains {
+				if domain.Host != host {
+					continue
+				}
+				if domain.IsFree {
+					o.CheckErr(utl.Errorf(utl.KindValidation, "%s is a free domain of route %q. Delete the route itself to remove it", host, domain.Name))
+				}
+				serverBase := getArvanPaasServerBase(o.Config)
+				routeKind, err := getBundleKind("Route")
+				o.CheckErr(err)
+				obj, err := getProjectObject(o, serverBase, namespace, routeKind, domain.Name)
+				o.CheckErr(err)
+
+				// only routes created for the domain are deleted, others get a generated host again
+				metadata, _ := obj["metadata"].(map[string]interface{})
+				if labels, _ := metadata["labels"].(map[string]interface{}); labels[domainLabel] == "true" {
+					o.CheckErr(deleteProjectObject(o, serverBase, namespace, routeKind, domain.Name))
+					fmt.Fprintf(o.Out, "Route %q serving %s deleted.\n", domain.Name, host)
+					return
+				}
+				spec, _ := obj["spec"].(map[string]interface{})
+				delete(spec, "host")
+				o.CheckErr(putProjectObject(o, serverBase, namespace, routeKind, obj))
+				fmt.Fprintf(o.Out, "Route %q no longer serves %s, a free domain is generated for it.\n", domain.Name, host)
+				return
+			}
+			o.CheckErr(utl.Errorf(utl.KindNotFound, "no route serves %s in project %q", host, namespace))
+		},
+	}
+
+	return cmd
+}
+
+// newCmdDomainVerify returns new cobra commad checking DNS records of custom domains.
+func newCmdDomainVerify(o *options.Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify [DOMAIN...]",
+		Short: "Check custom domains point to the gateway of the region",
+		Long: `
+    Check DNS records of custom domains of the current project, or the given domains, point to the gateway
+    of the region, either by a CNAME record or by resolving to the same addresses.`,
+		Run: func(c *cobra.Command, args []string) {
+			namespace, err := commandNamespace(c)
+			o.CheckErr(err)
+			info, err := getProjectDomains(o, namespace)
+			o.CheckErr(err)
+			if len(info.Gateway) == 0 {
+				o.CheckErr(utl.Errorf(utl.KindServer, "gateway of project %q is unknown, it has no admitted routes", namespace))
+			}
+
+			hosts := args
+			if len(hosts) == 0 {
+				for _, domain := range info.Domains {
+					if !domain.IsFree {
+						hosts = append(hosts, domain.Host)
+					}
+				}
+			}
+			if len(hosts) == 0 {
+				fmt.Fprintf(o.Out, "Project %q has no custom domains.\n", namespace)
+				return
+			}
+
+			var verifications []DomainVerification
+			failed := 0
+			for _, host := range hosts {
+				verification := verifyDomain(context.Background(), resolver, strings.ToLower(strings.TrimSuffix(host, ".")), info.Gateway)
+				if verification.Status != domainStatusOK {
+					failed++
+				}
+				verifications = append(verifications, verification)
+			}
+			sprintVerifications(o.Out, verifications)
+			if failed > 0 {
+				o.CheckErr(utl.Errorf(utl.KindValidation, "%d of %d domains are not pointed to %s", failed, len(verifications), info.Gateway))
+			}
+		},
+	}
+
+	return cmd
+}
+
+// getProjectDomains returns domains of routes of project, and the gateway custom domains should point to.
+func getProjectDomains(o *options.Options, project string) (*ZoneInfo, error) {
+	var list routeList
+	err := paasRequest(o, http.MethodGet, getArvanPaasServerBase(o.Config)+fmt.Sprintf(routesPath, url.PathEscape(project)), &list)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &ZoneInfo{Domains: []Domain{}}
+	for _, item := range list.Items {
+		if len(item.Spec.Host) == 0 {
+			continue
+		}
+		info.Domains = append(info.Domains, Domain{
+			Name:   item.Metadata.Name,
+			Host:   item.Spec.Host,
+			IsFree: item.Metadata.Annotations[hostGeneratedAnnotation] == "true",
+		})
+		for _, ingress := range item.Status.Ingress {
+			if len(info.Gateway) == 0 && len(ingress.RouterCanonicalHostname) > 0 {
+				info.Gateway = ingress.RouterCanonicalHostname
+			}
+		}
+	}
+	sort.Slice(info.Domains, func(i, j int) bool {
+		return info.Domains[i].Host < info.Domains[j].Host
+	})
+	return info, nil
+}
+
+// verifyDomain checks host points to gateway, either by a CNAME record or by resolving to addresses of gateway.
+func verifyDomain(ctx context.Context, r domainResolver, host, gateway string) DomainVerification {
+	ctx, cancel := context.WithTimeout(ctx, dnsLookupTimeout)
+	defer cancel()
+
+	verification := DomainVerification{Host: host, Gateway: gateway}
+	cname, err := r.LookupCNAME(ctx, host)
+	if err == nil && strings.TrimSuffix(cname, ".") != host {
+		verification.ResolvesTo = strings.TrimSuffix(cname, ".")
+		if strings.EqualFold(verification.ResolvesTo, strings.TrimSuffix(gateway, ".")) {
+			verification.Status = domainStatusOK
+			return verification
+		}
+	}
+
+	addresses, err := r.LookupHost(ctx, host)
+	if err != nil || len(addresses) == 0 {
+		verification.Status = domainStatusNotFound
+		return verification
+	}
+	if len(verification.ResolvesTo) == 0 {
+		verification.ResolvesTo = strings.Join(addresses, ",")
+	}
+	gatewayAddresses, err := r.LookupHost(ctx, gateway)
+	if err != nil {
+		verification.Status = domainStatusWrong
+		return verification
+	}
+	verification.Status = domainStatusWrong
+	for _, address := range addresses {
+		for _, gatewayAddress := range gatewayAddresses {
+			if address == gatewayAddress {
+				verification.Status = domainStatusOK
+			}
+		}
+	}
+	return verification
+}
+
+// sprintDomains displays domains of routes in columns, followed by the gateway.
+func sprintDomains(out io.Writer, info ZoneInfo) {
+	w := new(tabwriter.Writer)
+	w.Init(out, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintln(w, "DOMAIN\tTYPE\tROUTE")
+	for _, domain := range info.Domains {
+		domainType := "custom"
+		if domain.IsFree {
+			domainType = "free"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", domain.Host, domainType, domain.Name)
+	}
+	w.Flush()
+
+	if len(info.Gateway) > 0 {
+		fmt.Fprintf(out, "\nCustom domains should point to %s by a CNAME record.\n", info.Gateway)
+	}
+}
+
+// sprintVerifications displays results of verifying domains in columns.
+func sprintVerifications(out io.Writer, verifications []DomainVerification) {
+	w := new(tabwriter.Writer)
+	w.Init(out, 0, 8, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "DOMAIN\tRESOLVES TO\tGATEWAY\tSTATUS")
+	for _, v := range verifications {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", v.Host, sprintValue(v.ResolvesTo), v.Gateway, v.Status)
+	}
+}
@@ -0,0 +1,239 @@
+package paas_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arvancloud/cli/pkg/cli/clitest"
+	"github.com/arvancloud/cli/pkg/paas"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+// fakeResolver resolves hosts using CNAME records of cnames and addresses of hosts.
+type fakeResolver struct {
+	cnames map[string]string
+	hosts  map[string][]string
+}
+
+func (r fakeResolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	if cname, ok := r.cnames[host]; ok {
+		return cname, nil
+	}
+	if _, ok := r.hosts[host]; ok {
+		// like net.Resolver, the canonical name of a host without CNAME record is itself
+		return host + ".", nil
+	}
+	return "", &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func (r fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if cname, ok := r.cnames[host]; ok {
+		return r.LookupHost(ctx, strings.TrimSuffix(cname, "."))
+	}
+	if addresses, ok := r.hosts[host]; ok {
+		return addresses, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func TestVerifyDomain(t *testing.T) {
+	const gateway = "gw.ir-thr-at1.arvan.run"
+	r := fakeResolver{
+		cnames: map[string]string{
+			"shop.example.com":  gateway + ".",
+			"upper.example.com": strings.ToUpper(gateway) + ".",
+			"other.example.com": "lb.example.net.",
+		},
+		hosts: map[string][]string{
+			gateway:             {"185.0.0.1", "185.0.0.2"},
+			"lb.example.net":    {"10.0.0.2"},
+			"example.com":       {"185.0.0.2"},
+			"wrong.example.com": {"10.0.0.1"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		host       string
+		resolvesTo string
+		status     string
+	}{
+		{name: "CNAME match", host: "shop.example.com", resolvesTo: gateway, status: paas.DomainStatusOK},
+		{name: "CNAME match ignoring case", host: "upper.example.com", resolvesTo: strings.ToUpper(gateway), status: paas.DomainStatusOK},
+		{name: "A record match", host: "example.com", resolvesTo: "185.0.0.2", status: paas.DomainStatusOK},
+		{name: "CNAME mismatch", host: "other.example.com", resolvesTo: "lb.example.net", status: paas.DomainStatusWrong},
+		{name: "A record mismatch", host: "wrong.example.com", resolvesTo: "10.0.0.1", status: paas.DomainStatusWrong},
+		{name: "NXDOMAIN", host: "missing.example.com", status: paas.DomainStatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expected := paas.DomainVerification{Host: test.host, Gateway: gateway, ResolvesTo: test.resolvesTo, Status: test.status}
+			if actual := paas.VerifyDomain(context.Background(), r, test.host, gateway); actual != expected {
+				t.Errorf("verifyDomain(%q) = %+v, want %+v", test.host, actual, expected)
+			}
+		})
+	}
+}
+
+const routesKey = "at1/apis/route.openshift.io/v1/namespaces/shop/routes"
+
+// route returns a route named name serving host, labeled by labels.
+func route(name, host string, labels map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{"name": name, "labels": labels},
+		"spec": map[string]interface{}{
+			"host": host,
+			"to":   map[string]interface{}{"kind": "Service", "name": "web"},
+		},
+	}
+}
+
+// generatedRoute returns a route named name serving a free domain, admitted by router of gateway.
+func generatedRoute(name, host, gateway string) map[string]interface{} {
+	r := route(name, host, map[string]interface{}{"app": name})
+	r["metadata"].(map[string]interface{})["annotations"] = map[string]interface{}{
+		"openshift.io/host.generated": "true",
+		"description":                 "storefront",
+	}
+	r["status"] = map[string]interface{}{"ingress": []interface{}{
+		map[string]interface{}{"host": host, "routerCanonicalHostname": gateway},
+	}}
+	return r
+}
+
+func TestDomainList(t *testing.T) {
+	const gateway = "gw.ir-thr-at1.arvan.run"
+	h := clitest.New(t)
+	h.Server.Objects[routesKey] = []map[string]interface{}{
+		generatedRoute("web", "web-shop.apps.ir-thr-at1.arvan.run", gateway),
+		route("shop-example-com", "shop.example.com", map[string]interface{}{"arvan.ir/domain": "true"}),
+		route("pending", "", nil),
+	}
+
+	result := h.Run("", "paas", "domain", "list", "--namespace", "shop")
+	if result.ExitCode != 0 {
+		t.Fatalf("listing domains failed:\n%s", result)
+	}
+	expected := fmt.Sprintf("%-36s%-8s%s\n", "DOMAIN", "TYPE", "ROUTE") +
+		fmt.Sprintf("%-36s%-8s%s\n", "shop.example.com", "custom", "shop-example-com") +
+		fmt.Sprintf("%-36s%-8s%s\n", "web-shop.apps.ir-thr-at1.arvan.run", "free", "web") +
+		"\nCustom domains should point to " + gateway + " by a CNAME record.\n"
+	if result.Stdout != expected {
+		t.Errorf("output:\n%s\nwant:\n%s", result.Stdout, expected)
+	}
+}
+
+func TestDomainAddService(t *testing.T) {
+	const gateway = "gw.ir-thr-at1.arvan.run"
+	h := clitest.New(t)
+	h.Server.Objects[routesKey] = []map[string]interface{}{generatedRoute("web", "web-shop.apps.ir-thr-at1.arvan.run", gateway)}
+
+	result := h.Run("", "paas", "domain", "add", "Shop.Example.com.", "--service", "web", "--tls", "--namespace", "shop")
+	if result.ExitCode != 0 {
+		t.Fatalf("adding domain failed:\n%s", result)
+	}
+	for _, expected := range []string{
+		`Route "shop-example-com" serves shop.example.com.`,
+		"Point shop.example.com to " + gateway + " by a CNAME record",
+	} {
+		if !strings.Contains(result.Stdout, expected) {
+			t.Errorf("output does not contain %q:\n%s", expected, result)
+		}
+	}
+
+	routes := h.Server.Objects[routesKey]
+	if len(routes) != 2 {
+		t.Fatalf("routes = %v, want a new route", routes)
+	}
+	metadata := routes[1]["metadata"].(map[string]interface{})
+	if metadata["name"] != "shop-example-com" || metadata["labels"].(map[string]interface{})["arvan.ir/domain"] != "true" {
+		t.Errorf("metadata of the new route = %v, want shop-example-com labeled arvan.ir/domain=true", metadata)
+	}
+	spec := routes[1]["spec"].(map[string]interface{})
+	if spec["host"] != "shop.example.com" || spec["to"].(map[string]interface{})["name"] != "web" {
+		t.Errorf("spec of the new route = %v, want service web served on shop.example.com", spec)
+	}
+	if tls, _ := spec["tls"].(map[string]interface{}); tls["termination"] != "edge" {
+		t.Errorf("tls of the new route = %v, want edge termination", spec["tls"])
+	}
+}
+
+func TestDomainAddRoute(t *testing.T) {
+	h := clitest.New(t)
+	h.Server.Objects[routesKey] = []map[string]interface{}{generatedRoute("web", "web-shop.apps.ir-thr-at1.arvan.run", "gw.ir-thr-at1.arvan.run")}
+
+	result := h.Run("", "paas", "domain", "add", "www.example.com", "--route", "web", "--namespace", "shop")
+	if result.ExitCode != 0 {
+		t.Fatalf("adding domain failed:\n%s", result)
+	}
+
+	routes := h.Server.Objects[routesKey]
+	if len(routes) != 1 {
+		t.Fatalf("routes = %v, want route web to be updated only", routes)
+	}
+	if host := routes[0]["spec"].(map[string]interface{})["host"]; host != "www.example.com" {
+		t.Errorf("host of route web = %v, want www.example.com", host)
+	}
+	metadata := routes[0]["metadata"].(map[string]interface{})
+	expected := map[string]interface{}{"description": "storefront"}
+	if annotations := metadata["annotations"].(map[string]interface{}); !reflect.DeepEqual(annotations, expected) {
+		t.Errorf("annotations of route web = %v, want only the generated host annotation to be dropped", annotations)
+	}
+	if labels := metadata["labels"].(map[string]interface{}); labels["arvan.ir/domain"] != nil {
+		t.Errorf("labels of route web = %v, want it not to be labeled as a route of the domain", labels)
+	}
+}
+
+func TestDomainAddInvalidFlags(t *testing.T) {
+	for _, args := range [][]string{{}, {"--service", "web", "--route", "web"}} {
+		h := clitest.New(t)
+		result := h.Run("", append([]string{"paas", "domain", "add", "shop.example.com", "--namespace", "shop"}, args...)...)
+		if result.ExitCode != utl.ValidationErrorExitCode {
+			t.Errorf("exit code of adding domain with %v = %d, want %d:\n%s", args, result.ExitCode, utl.ValidationErrorExitCode, result)
+		}
+	}
+}
+
+func TestDomainRemove(t *testing.T) {
+	h := clitest.New(t)
+	h.Server.Objects[routesKey] = []map[string]interface{}{
+		route("shop-example-com", "shop.example.com", map[string]interface{}{"arvan.ir/domain": "true"}),
+		route("web", "www.example.com", map[string]interface{}{"app": "web"}),
+	}
+
+	result := h.Run("", "paas", "domain", "remove", "shop.example.com", "--namespace", "shop")
+	if result.ExitCode != 0 {
+		t.Fatalf("removing domain failed:\n%s", result)
+	}
+	if !strings.Contains(result.Stdout, `Route "shop-example-com" serving shop.example.com deleted.`) {
+		t.Errorf("deletion is not reported:\n%s", result)
+	}
+
+	result = h.Run("", "paas", "domain", "remove", "www.example.com", "--namespace", "shop")
+	if result.ExitCode != 0 {
+		t.Fatalf("removing domain failed:\n%s", result)
+	}
+	if !strings.Contains(result.Stdout, `Route "web" no longer serves www.example.com`) {
+		t.Errorf("reset of host is not reported:\n%s", result)
+	}
+
+	routes := h.Server.Objects[routesKey]
+	if len(routes) != 1 {
+		t.Fatalf("routes = %v, want only route web to be kept", routes)
+	}
+	if name := routes[0]["metadata"].(map[string]interface{})["name"]; name != "web" {
+		t.Errorf("kept route = %v, want web", name)
+	}
+	spec := routes[0]["spec"].(map[string]interface{})
+	if host, ok := spec["host"]; ok {
+		t.Errorf("host of route web = %v, want it to be reset", host)
+	}
+	if spec["to"] == nil {
+		t.Errorf("spec of route web = %v, want only its host to be reset", spec)
+	}
+}
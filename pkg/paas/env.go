@@ -0,0 +1,620 @@
+package paas
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arvancloud/cli/pkg/options"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+const (
+	defaultEnvFileName = ".env"
+	maskedEnvValue     = "********"
+)
+
+var (
+	envPushLong = `
+    Set environment variables of a workload from a .env file
+
+    Each line of the file is either empty, a comment starting with '#', or NAME=VALUE. Values may be
+    quoted, and lines may be prefixed by "export". Variables are set on all containers of the workload,
+    keeping variables not in the file unless --prune is given.
+
+    Use --as-secret to store variables in a secret instead, which is loaded by containers of the workload.
+    Changes are previewed before being applied; values of secrets and variables named like passwords,
+    tokens or keys are masked unless --show-values is given.`
+
+	envPullLong = `
+    Write environment variables of a workload to a .env file
+
+    Variables of the first container of the workload are written, and variables set from secrets or
+    configmaps are written as comments. Changes to an existing file are previewed and confirmed before
+    it's overwritten, unless --force is given; use "-f -" to print variables instead.`
+
+	envNameRegexp       = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+	sensitiveNameRegexp = regexp.MustCompile(`(?i)(pass|secret|token|key|credential|private)`)
+)
+
+// workloadAliases maps resource names of workloads accepted by env commands e.g "dc" to their kind.
+var workloadAliases = map[string]string{
+	"dc":                "deploymentconfigs",
+	"deploymentconfig":  "deploymentconfigs",
+	"deploymentconfigs": "deploymentconfigs",
+	"deploy":            "deployments",
+	"deployment":        "deployments",
+	"deployments":       "deployments",
+	"sts":               "statefulsets",
+	"statefulset":       "statefulsets",
+	"statefulsets":      "statefulsets",
+}
+
+// envChange is a variable added (+), removed (-) or changed (~) by pushing or pulling env.
+type envChange struct {
+	op       string
+	name     string
+	oldValue string
+	newValue string
+}
+
+// NewCmdEnv returns new cobra commad managing environment variables of workloads using .env files.
+func NewCmdEnv(o *options.Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "env",
+		Short: "Manage environment variables of workloads using .env files",
+	}
+
+	cmd.AddCommand(newCmdEnvPush(o))
+	cmd.AddCommand(newCmdEnvPull(o))
+
+	return cmd
+}
+
+// newCmdEnvPush returns new cobra commad setting environment variables of a workload from a .env file.
+func newCmdEnvPush(o *options.Options) *cobra.Command {
+	var filename, to, secretName string
+	var prune, dryRun, showValues bool
+	cmd := &cobra.Command{
+		Use:     "push",
+		Short:   "Set environment variables of a workload from a .env file",
+		Long:    envPushLong,
+		Example: "  arvan paas env push -f .env.production --to dc/web --as-secret web-env --prune",
+		Args:    cobra.NoArgs,
+		Run: func(c *cobra.Command, args []string) {
+			namespace, err := commandNamespace(c)
+			o.CheckErr(err)
+			k, name, err := parseWorkload(to)
+			o.CheckErr(err)
+
+			file, err := os.Open(filename)
+			o.CheckErr(err)
+			env, err := parseEnvFile(file)
+			file.Close()
+			o.CheckErr(err)
+
+			serverBase := getArvanPaasServerBase(o.Config)
+			workload, err := getProjectObject(o, serverBase, namespace, k, name)
+			o.CheckErr(err)
+			containers := workloadContainers(workload)
+			if len(containers) == 0 {
+				o.CheckErr(utl.Errorf(utl.KindValidation, "%s has no containers", to))
+			}
+
+			if len(secretName) > 0 {
+				o.CheckErr(pushEnvSecret(o, serverBase, namespace, secretName, to, k, workload, env, prune, dryRun, showValues))
+				return
+			}
+
+			changed := false
+			for _, container := range containers {
+				if len(containers) > 1 {
+					fmt.Fprintf(o.Out, "container %v:\n", container["name"])
+				}
+				current, _ := containerEnv(container)
+				changes := diffEnv(current, mergeEnv(current, env, prune))
+				printEnvChanges(o.Out, changes, func(name string) bool {
+					return !showValues && sensitiveNameRegexp.MatchString(name)
+				})
+				if len(changes) > 0 {
+					changed = true
+				}
+			}
+			if !changed || dryRun {
+				return
+			}
+
+			for _, container := range containers {
+				setContainerEnv(container, env, prune)
+			}
+			o.CheckErr(putProjectObject(o, serverBase, namespace, k, workload))
+			fmt.Fprintf(o.Out, "Environment of %s updated.\n", to)
+		},
+	}
+
+	cmd.Flags().StringVarP(&filename, "filename", "f", defaultEnvFileName, "The .env file to push")
+	cmd.Flags().StringVar(&to, "to", "", "Workload to set variables of e.g dc/web or deployment/web")
+	cmd.Flags().StringVar(&secretName, "as-secret", "", "Store variables in the secret of this name, loaded by the workload")
+	cmd.Flags().BoolVar(&prune, "prune", false, "Remove variables not in the file")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview changes without applying them")
+	cmd.Flags().BoolVar(&showValues, "show-values", false, "Show values of secrets and sensitive variables in the preview")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+// newCmdEnvPull returns new cobra commad writing environment variables of a workload to a .env file.
+func newCmdEnvPull(o *options.Options) *cobra.Command {
+	var filename, from string
+	var showValues, force bool
+	cmd := &cobra.Command{
+		Use:     "pull",
+		Short:   "Write environment variables of a workload to a .env file",
+		Long:    envPullLong,
+		Example: "  arvan paas env pull --from dc/web -f .env.production",
+		Args:    cobra.NoArgs,
+		Run: func(c *cobra.Command, args []string) {
+			namespace, err := commandNamespace(c)
+			o.CheckErr(err)
+			k, name, err := parseWorkload(from)
+			o.CheckErr(err)
+
+			workload, err := getProjectObject(o, getArvanPaasServerBase(o.Config), namespace, k, name)
+			o.CheckErr(err)
+			containers := workloadContainers(workload)
+			if len(containers) == 0 {
+				o.CheckErr(utl.Errorf(utl.KindValidation, "%s has no containers", from))
+			}
+			env, references := containerEnv(containers[0])
+
+			if filename == "-" {
+				o.CheckErr(writeEnvFile(o.Out, env, references))
+				return
+			}
+
+			if file, err := os.Open(filename); err == nil {
+				current, err := parseEnvFile(file)
+				file.Close()
+				o.CheckErr(err)
+				changes := diffEnv(current, env)
+				printEnvChanges(o.Out, changes, func(name string) bool {
+					return !showValues && sensitiveNameRegexp.MatchString(name)
+				})
+				if len(changes) == 0 {
+					return
+				}
+				if !force {
+					confirmed, err := overwriteEnvFileConfirm(filename, o.In, o.Out)
+					o.CheckErr(err)
+					if !confirmed {
+						o.CheckErr(utl.Errorf(utl.KindUserAborted, "%s is not overwritten", filename))
+					}
+				}
+			}
+
+			// the file may hold values of secrets, so only its owner can read it
+			file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+			o.CheckErr(err)
+			err = writeEnvFile(file, env, references)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			o.CheckErr(err)
+			fmt.Fprintf(o.Out, "Environment of %s written to %s.\n", from, filename)
+		},
+	}
+
+	cmd.Flags().StringVarP(&filename, "filename", "f", defaultEnvFileName, "The .env file to write, or - to print variables")
+	cmd.Flags().StringVar(&from, "from", "", "Workload to read variables of e.g dc/web or deployment/web")
+	cmd.Flags().BoolVar(&showValues, "show-values", false, "Show values of sensitive variables in the preview")
+	cmd.Flags().BoolVar(&force, forceFlag, false, "Overwrite an existing file without confirmation")
+	_ = cmd.MarkFlagRequired("from")
+
+	return cmd
+}
+
+// overwriteEnvFileConfirm asks user to confirm overwriting .env file named filename.
+func overwriteEnvFileConfirm(filename string, in io.Reader, writer io.Writer) (bool, error) {
+	inputExplain := fmt.Sprintf("Do you want to overwrite %s?[y/N]: ", filename)
+
+	defaultVal := "N"
+
+	value, err := utl.ReadInput(inputExplain, defaultVal, writer, in, confirmationValidate)
+	return value == "y", err
+}
+
+// pushEnvSecret stores env in secret named secretName and makes containers of workload load it.
+func pushEnvSecret(o *options.Options, serverBase, namespace, secretName, to string, k bundleKind, workload object, env map[string]string, prune, dryRun, showValues bool) error {
+	secretKind, err := getBundleKind("Secret")
+	if err != nil {
+		return err
+	}
+	secret, err := getProjectObject(o, serverBase, namespace, secretKind, secretName)
+	exists := err == nil
+	if utl.KindOf(err) == utl.KindNotFound {
+		secret = object{
+			"apiVersion": secretKind.apiVersion,
+			"kind":       secretKind.kind,
+			"metadata":   map[string]interface{}{"name": secretName},
+			"type":       "Opaque",
+		}
+	} else if err != nil {
+		return err
+	}
+
+	current := map[string]string{}
+	data, _ := secret["data"].(map[string]interface{})
+	for name, value := range data {
+		decoded, err := base64.StdEncoding.DecodeString(fmt.Sprint(value))
+		if err != nil {
+			return utl.Errorf(utl.KindServer, "invalid value of %q in secret %q: %v", name, secretName, err)
+		}
+		current[name] = string(decoded)
+	}
+
+	desired := mergeEnv(current, env, prune)
+	changes := diffEnv(current, desired)
+	printEnvChanges(o.Out, changes, func(string) bool {
+		return !showValues
+	})
+
+	containers := workloadContainers(workload)
+	referenced := true
+	for _, container := range containers {
+		if !containerLoadsSecret(container, secretName) {
+			referenced = false
+		}
+	}
+	if !referenced {
+		fmt.Fprintf(o.Out, "%s will load secret %q.\n", to, secretName)
+	}
+	if dryRun || (len(changes) == 0 && referenced) {
+		return nil
+	}
+
+	encoded := map[string]interface{}{}
+	for name, value := range desired {
+		encoded[name] = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	secret["data"] = encoded
+	delete(secret, "stringData")
+	if exists {
+		err = putProjectObject(o, serverBase, namespace, secretKind, secret)
+	} else {
+		err = createProjectObject(o, serverBase, namespace, secretKind, secret)
+	}
+	if err != nil {
+		return err
+	}
+
+	if referenced {
+		fmt.Fprintf(o.Out, "Secret %q updated. Roll out %s to load the changes in running pods.\n", secretName, to)
+		return nil
+	}
+	for _, container := range containers {
+		if !containerLoadsSecret(container, secretName) {
+			envFrom, _ := container["envFrom"].([]interface{})
+			container["envFrom"] = append(envFrom, map[string]interface{}{"secretRef": map[string]interface{}{"name": secretName}})
+		}
+	}
+	if err = putProjectObject(o, serverBase, namespace, k, workload); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "Secret %q updated and loaded by %s.\n", secretName, to)
+	return nil
+}
+
+// commandNamespace returns project of c given by --namespace, or of current context of its kubeconfig.
+func commandNamespace(c *cobra.Command) (string, error) {
+	if namespace, err := c.Flags().GetString("namespace"); err == nil && len(namespace) > 0 {
+		return namespace, nil
+	}
+	if f := c.Flags().Lookup("kubeconfig"); f != nil {
+		if namespace := currentNamespace(f.Value.String()); len(namespace) > 0 {
+			return namespace, nil
+		}
+	}
+	return "", utl.Errorf(utl.KindValidation, "no project selected. Use --namespace or \"arvan paas project NAME\" to select one")
+}
+
+// parseWorkload returns kind and name of workload e.g "dc/web".
+func parseWorkload(workload string) (bundleKind, string, error) {
+	parts := strings.SplitN(workload, "/", 2)
+	if len(parts) == 2 && len(parts[1]) > 0 {
+		if resource, ok := workloadAliases[strings.ToLower(parts[0])]; ok {
+			for _, k := range bundleKinds {
+				if k.resource == resource {
+					return k, parts[1], nil
+				}
+			}
+		}
+	}
+	return bundleKind{}, "", utl.Errorf(utl.KindValidation, "invalid workload %q. Use TYPE/NAME e.g dc/web, deployment/web or statefulset/web", workload)
+}
+
+// getProjectObject returns object of kind k named name from project in paas api of serverBase.
+func getProjectObject(o *options.Options, serverBase, project string, k bundleKind, name string) (object, error) {
+	endpoint := serverBase + fmt.Sprintf(k.path, url.PathEscape(project)) + "/" + url.PathEscape(name)
+	var obj object
+	if err := paasRequest(o, http.MethodGet, endpoint, &obj); err != nil {
+		if utl.KindOf(err) == utl.KindNotFound {
+			return nil, utl.Errorf(utl.KindNotFound, "%s/%s not found in project %q", k.resource, name, project)
+		}
+		return nil, err
+	}
+	return obj, nil
+}
+
+// putProjectObject replaces obj of kind k in project in paas api of serverBase. It fails if obj has changed since it was read.
+func putProjectObject(o *options.Options, serverBase, project string, k bundleKind, obj object) error {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	endpoint := serverBase + fmt.Sprintf(k.path, url.PathEscape(project)) + "/" + url.PathEscape(objectName(obj))
+	return paasRequestWithBody(o, http.MethodPut, endpoint, "application/json", body, nil)
+}
+
+// createProjectObject creates obj of kind k in project in paas api of serverBase.
+func createProjectObject(o *options.Options, serverBase, project string, k bundleKind, obj object) error {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	endpoint := serverBase + fmt.Sprintf(k.path, url.PathEscape(project))
+	return paasRequestWithBody(o, http.MethodPost, endpoint, "application/json", body, nil)
+}
+
+// workloadContainers returns containers of pod template of workload.
+func workloadContainers(workload object) []map[string]interface{} {
+	spec, _ := workload["spec"].(map[string]interface{})
+	template, _ := spec["template"].(map[string]interface{})
+	podSpec, _ := template["spec"].(map[string]interface{})
+	items, _ := podSpec["containers"].([]interface{})
+
+	var containers []map[string]interface{}
+	for _, item := range items {
+		if container, ok := item.(map[string]interface{}); ok {
+			containers = append(containers, container)
+		}
+	}
+	return containers
+}
+
+// containerEnv returns variables of container set by value, and descriptions of variables set from secrets or configmaps.
+func containerEnv(container map[string]interface{}) (map[string]string, []string) {
+	env := map[string]string{}
+	var references []string
+	items, _ := container["env"].([]interface{})
+	for _, item := range items {
+		variable, _ := item.(map[string]interface{})
+		name, _ := variable["name"].(string)
+		if valueFrom, ok := variable["valueFrom"].(map[string]interface{}); ok {
+			references = append(references, fmt.Sprintf("%s is set from %s", name, sprintValueFrom(valueFrom)))
+			continue
+		}
+		value, _ := variable["value"].(string)
+		env[name] = value
+	}
+	envFrom, _ := container["envFrom"].([]interface{})
+	for _, item := range envFrom {
+		source, _ := item.(map[string]interface{})
+		for _, ref := range []string{"secretRef", "configMapRef"} {
+			if r, ok := source[ref].(map[string]interface{}); ok {
+				references = append(references, fmt.Sprintf("variables are loaded from %s/%v", strings.TrimSuffix(strings.ToLower(ref), "ref"), r["name"]))
+			}
+		}
+	}
+	return env, references
+}
+
+// sprintValueFrom describes source of a variable e.g "secret db key password".
+func sprintValueFrom(valueFrom map[string]interface{}) string {
+	for _, ref := range []string{"secretKeyRef", "configMapKeyRef"} {
+		if r, ok := valueFrom[ref].(map[string]interface{}); ok {
+			return fmt.Sprintf("%s/%v key %v", strings.TrimSuffix(strings.ToLower(ref), "keyref"), r["name"], r["key"])
+		}
+	}
+	for source := range valueFrom {
+		return source
+	}
+	return "unknown source"
+}
+
+// containerLoadsSecret reports whether container loads variables of secret named name.
+func containerLoadsSecret(container map[string]interface{}, name string) bool {
+	envFrom, _ := container["envFrom"].([]interface{})
+	for _, item := range envFrom {
+		source, _ := item.(map[string]interface{})
+		if ref, ok := source["secretRef"].(map[string]interface{}); ok && ref["name"] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// setContainerEnv sets variables of env on container, keeping order of existing variables. If prune is set,
+// variables set by value which are not in env are removed.
+func setContainerEnv(container map[string]interface{}, env map[string]string, prune bool) {
+	items, _ := container["env"].([]interface{})
+	result := []interface{}{}
+	set := map[string]bool{}
+	for _, item := range items {
+		variable, _ := item.(map[string]interface{})
+		name, _ := variable["name"].(string)
+		value, inFile := env[name]
+		switch {
+		case inFile:
+			result = append(result, map[string]interface{}{"name": name, "value": value})
+			set[name] = true
+		case prune && variable["valueFrom"] == nil:
+		default:
+			result = append(result, item)
+		}
+	}
+	for _, name := range sortedEnvNames(env) {
+		if !set[name] {
+			result = append(result, map[string]interface{}{"name": name, "value": env[name]})
+		}
+	}
+	container["env"] = result
+}
+
+// mergeEnv returns variables of current overridden by env, or env itself if prune is set.
+func mergeEnv(current, env map[string]string, prune bool) map[string]string {
+	result := map[string]string{}
+	if !prune {
+		for name, value := range current {
+			result[name] = value
+		}
+	}
+	for name, value := range env {
+		result[name] = value
+	}
+	return result
+}
+
+// diffEnv returns variables added, removed or changed from current to desired, sorted by name.
+func diffEnv(current, desired map[string]string) []envChange {
+	var changes []envChange
+	for name, value := range desired {
+		oldValue, ok := current[name]
+		switch {
+		case !ok:
+			changes = append(changes, envChange{op: "+", name: name, newValue: value})
+		case oldValue != value:
+			changes = append(changes, envChange{op: "~", name: name, oldValue: oldValue, newValue: value})
+		}
+	}
+	for name, value := range current {
+		if _, ok := desired[name]; !ok {
+			changes = append(changes, envChange{op: "-", name: name, oldValue: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].name < changes[j].name
+	})
+	return changes
+}
+
+// printEnvChanges prints changes, replacing values of variables masked by mask.
+func printEnvChanges(out io.Writer, changes []envChange, mask func(name string) bool) {
+	if len(changes) == 0 {
+		fmt.Fprintln(out, "No changes.")
+		return
+	}
+	for _, change := range changes {
+		oldValue, newValue := change.oldValue, change.newValue
+		if mask(change.name) {
+			oldValue, newValue = maskedEnvValue, maskedEnvValue
+		}
+		switch change.op {
+		case "+":
+			fmt.Fprintf(out, "+ %s=%s\n", change.name, newValue)
+		case "-":
+			fmt.Fprintf(out, "- %s=%s\n", change.name, oldValue)
+		default:
+			fmt.Fprintf(out, "~ %s=%s -> %s\n", change.name, oldValue, newValue)
+		}
+	}
+}
+
+// parseEnvFile reads variables of a .env file in r.
+func parseEnvFile(r io.Reader) (map[string]string, error) {
+	env := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimSpace(strings.TrimPrefix(text, "export "))
+
+		parts := strings.SplitN(text, "=", 2)
+		name := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || !envNameRegexp.MatchString(name) {
+			return nil, utl.Errorf(utl.KindValidation, "invalid variable in line %d of .env file, expected NAME=VALUE", line)
+		}
+
+		value := strings.TrimSpace(parts[1])
+		switch {
+		case strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "'"):
+			quoted, rest, ok := splitQuoted(value)
+			rest = strings.TrimSpace(rest)
+			if !ok || (len(rest) > 0 && !strings.HasPrefix(rest, "#")) {
+				return nil, utl.Errorf(utl.KindValidation, "invalid quoted value of %s in line %d of .env file", name, line)
+			}
+			value = quoted[1 : len(quoted)-1]
+			if quoted[0] == '"' {
+				unquoted, err := strconv.Unquote(quoted)
+				if err != nil {
+					return nil, utl.Errorf(utl.KindValidation, "invalid quoted value of %s in line %d of .env file", name, line)
+				}
+				value = unquoted
+			}
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		env[name] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// splitQuoted splits value quoted by its first character at its closing quote, returning the quoted value and
+// the rest of value e.g a comment. A backslash escapes the next character of double quoted values.
+func splitQuoted(value string) (string, string, bool) {
+	quote := value[0]
+	for i := 1; i < len(value); i++ {
+		switch {
+		case quote == '"' && value[i] == '\\':
+			i++
+		case value[i] == quote:
+			return value[:i+1], value[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// writeEnvFile writes env sorted by name to w in .env format, preceded by comments.
+func writeEnvFile(w io.Writer, env map[string]string, comments []string) error {
+	for _, comment := range comments {
+		if _, err := fmt.Fprintf(w, "# %s\n", comment); err != nil {
+			return err
+		}
+	}
+	for _, name := range sortedEnvNames(env) {
+		value := env[name]
+		if strings.ContainsAny(value, " \t\n#'\"\\") {
+			value = strconv.Quote(value)
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedEnvNames(env map[string]string) []string {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
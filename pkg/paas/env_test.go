@@ -0,0 +1,388 @@
+package paas_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arvancloud/cli/pkg/cli/clitest"
+	"github.com/arvancloud/cli/pkg/paas"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+const (
+	deploymentConfigsKey = "at1/apis/apps.openshift.io/v1/namespaces/shop/deploymentconfigs"
+	secretsKey           = "at1/api/v1/namespaces/shop/secrets"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected map[string]string
+	}{
+		{name: "plain", content: "A=1\nB = two words \n", expected: map[string]string{"A": "1", "B": "two words"}},
+		{name: "comments and blank lines", content: "# comment\n\n  # indented\nA=1\n", expected: map[string]string{"A": "1"}},
+		{name: "export", content: "export A=1\n", expected: map[string]string{"A": "1"}},
+		{name: "empty value", content: "A=\n", expected: map[string]string{"A": ""}},
+		{name: "inline comment", content: "A=1 # one\nB=a#b\n", expected: map[string]string{"A": "1", "B": "a#b"}},
+		{name: "double quoted", content: `A="a b # c"` + "\n", expected: map[string]string{"A": "a b # c"}},
+		{name: "double quoted with comment", content: `A="a b" # comment` + "\n", expected: map[string]string{"A": "a b"}},
+		{name: "double quoted with escapes", content: `A="say \"hi\"\n" # comment` + "\n", expected: map[string]string{"A": "say \"hi\"\n"}},
+		{name: "single quoted", content: `A='a "b" \n'` + "\n", expected: map[string]string{"A": `a "b" \n`}},
+		{name: "single quoted with comment", content: `A='a b' # comment` + "\n", expected: map[string]string{"A": "a b"}},
+		{name: "value with equal sign", content: "A=b=c\n", expected: map[string]string{"A": "b=c"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env, err := paas.ParseEnvFile(strings.NewReader(test.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(env, test.expected) {
+				t.Errorf("env = %q, want %q", env, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseEnvFileErrors(t *testing.T) {
+	tests := map[string]string{
+		"missing value":             "A\n",
+		"invalid name":              "1A=1\n",
+		"unterminated double quote": `A="a b` + "\n",
+		"unterminated single quote": `A='a b` + "\n",
+		"text after quotes":         `A="a" b` + "\n",
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := paas.ParseEnvFile(strings.NewReader(content))
+			if utl.KindOf(err) != utl.KindValidation {
+				t.Errorf("error = %v, want a validation error", err)
+			}
+		})
+	}
+}
+
+func TestWriteEnvFile(t *testing.T) {
+	env := map[string]string{
+		"PLAIN":   "1",
+		"EMPTY":   "",
+		"SPACES":  "a b",
+		"COMMENT": "a #b",
+		"QUOTES":  `say "hi"`,
+		"NEWLINE": "a\nb",
+	}
+
+	out := &bytes.Buffer{}
+	if err := paas.WriteEnvFile(out, env, []string{"DB_PASSWORD is set from secret/db key password"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# DB_PASSWORD is set from secret/db key password
+COMMENT="a #b"
+EMPTY=
+NEWLINE="a\nb"
+PLAIN=1
+QUOTES="say \"hi\""
+SPACES="a b"
+`
+	if out.String() != expected {
+		t.Errorf("written file:\n%s\nwant:\n%s", out, expected)
+	}
+
+	parsed, err := paas.ParseEnvFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, env) {
+		t.Errorf("parsed env = %q, want %q", parsed, env)
+	}
+}
+
+// deploymentConfig returns a deployment config named web with a container per env of containersEnv.
+func deploymentConfig(containersEnv ...map[string]string) map[string]interface{} {
+	var containers []interface{}
+	for i, env := range containersEnv {
+		var items []interface{}
+		for name, value := range env {
+			items = append(items, map[string]interface{}{"name": name, "value": value})
+		}
+		containers = append(containers, map[string]interface{}{"name": []string{"web", "worker"}[i], "env": items})
+	}
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "web"},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{"spec": map[string]interface{}{"containers": containers}},
+		},
+	}
+}
+
+func TestEnvPushPreviewsEveryContainer(t *testing.T) {
+	h := clitest.New(t)
+	h.Server.Objects[deploymentConfigsKey] = []map[string]interface{}{
+		deploymentConfig(map[string]string{"MODE": "debug"}, map[string]string{"MODE": "release"}),
+	}
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := ioutil.WriteFile(envFile, []byte("MODE=release\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	result := h.Run("", "paas", "env", "push", "--to", "dc/web", "-f", envFile, "--namespace", "shop", "--dry-run")
+	if result.ExitCode != 0 {
+		t.Fatalf("env push failed:\n%s", result)
+	}
+	expected := "container web:\n~ MODE=debug -> release\ncontainer worker:\nNo changes.\n"
+	if !strings.Contains(result.Stdout, expected) {
+		t.Errorf("preview:\n%s\nwant:\n%s", result.Stdout, expected)
+	}
+}
+
+func TestEnvPullOverwrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		stdin    string
+		args     []string
+		exitCode int
+		content  string
+	}{
+		{name: "confirmed", stdin: "y\n", content: "MODE=release\n"},
+		{name: "declined", stdin: "N\n", exitCode: utl.UserAbortedErrorExitCode, content: "MODE=debug\n"},
+		{name: "no input", stdin: "", exitCode: utl.UserAbortedErrorExitCode, content: "MODE=debug\n"},
+		{name: "forced", args: []string{"--force"}, content: "MODE=release\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := clitest.New(t)
+			h.Server.Objects[deploymentConfigsKey] = []map[string]interface{}{deploymentConfig(map[string]string{"MODE": "release"})}
+			envFile := filepath.Join(t.TempDir(), ".env")
+			if err := ioutil.WriteFile(envFile, []byte("MODE=debug\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			args := append([]string{"paas", "env", "pull", "--from", "dc/web", "-f", envFile, "--namespace", "shop"}, test.args...)
+			result := h.Run(test.stdin, args...)
+			if result.ExitCode != test.exitCode {
+				t.Fatalf("exit code = %d, want %d:\n%s", result.ExitCode, test.exitCode, result)
+			}
+			if !strings.Contains(result.Stdout, "~ MODE=debug -> release") {
+				t.Errorf("changes are not previewed:\n%s", result)
+			}
+			content, err := ioutil.ReadFile(envFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != test.content {
+				t.Errorf("file content = %q, want %q", content, test.content)
+			}
+		})
+	}
+}
+
+func TestEnvPullNewFile(t *testing.T) {
+	h := clitest.New(t)
+	h.Server.Objects[deploymentConfigsKey] = []map[string]interface{}{deploymentConfig(map[string]string{"MODE": "release"})}
+	envFile := filepath.Join(t.TempDir(), ".env")
+
+	result := h.Run("", "paas", "env", "pull", "--from", "dc/web", "-f", envFile, "--namespace", "shop")
+	if result.ExitCode != 0 {
+		t.Fatalf("env pull failed:\n%s", result)
+	}
+	content, err := ioutil.ReadFile(envFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "MODE=release\n" {
+		t.Errorf("file content = %q, want MODE=release", content)
+	}
+	info, err := os.Stat(envFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("file mode = %v, want -rw-------", mode)
+	}
+}
+
+// writeEnvFile writes content to a .env file in a temporary directory and returns its path.
+func writeEnvFile(t *testing.T, content string) string {
+	t.Helper()
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := ioutil.WriteFile(envFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return envFile
+}
+
+// deploymentConfigEnv returns variables of the first container of deployment config web of h, set by value.
+func deploymentConfigEnv(t *testing.T, h *clitest.Harness) map[string]string {
+	t.Helper()
+	env := map[string]string{}
+	items, _ := firstContainer(t, h)["env"].([]interface{})
+	for _, item := range items {
+		variable := item.(map[string]interface{})
+		if value, ok := variable["value"].(string); ok {
+			env[variable["name"].(string)] = value
+		}
+	}
+	return env
+}
+
+// firstContainer returns the first container of deployment config web of h.
+func firstContainer(t *testing.T, h *clitest.Harness) map[string]interface{} {
+	t.Helper()
+	spec := h.Server.Objects[deploymentConfigsKey][0]["spec"].(map[string]interface{})
+	podSpec := spec["template"].(map[string]interface{})["spec"].(map[string]interface{})
+	return podSpec["containers"].([]interface{})[0].(map[string]interface{})
+}
+
+// updatedWorkload reports whether deployment config web is updated by requests of h.
+func updatedWorkload(h *clitest.Harness) bool {
+	for _, request := range h.Server.Requests() {
+		if strings.HasPrefix(request, "PUT ") && strings.HasSuffix(request, "/deploymentconfigs/web") {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEnvPush(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		args     []string
+		expected map[string]string
+		updated  bool
+	}{
+		{
+			name:     "merged",
+			content:  "MODE=release\nWORKERS=4\n",
+			expected: map[string]string{"MODE": "release", "DEBUG": "1", "WORKERS": "4"},
+			updated:  true,
+		},
+		{
+			name:     "pruned",
+			content:  "MODE=release\n",
+			args:     []string{"--prune"},
+			expected: map[string]string{"MODE": "release"},
+			updated:  true,
+		},
+		{
+			name:     "unchanged",
+			content:  "MODE=debug\n",
+			expected: map[string]string{"MODE": "debug", "DEBUG": "1"},
+		},
+		{
+			name:     "dry run",
+			content:  "MODE=release\n",
+			args:     []string{"--dry-run"},
+			expected: map[string]string{"MODE": "debug", "DEBUG": "1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := clitest.New(t)
+			h.Server.Objects[deploymentConfigsKey] = []map[string]interface{}{deploymentConfig(map[string]string{"MODE": "debug", "DEBUG": "1"})}
+			envFile := writeEnvFile(t, test.content)
+
+			args := append([]string{"paas", "env", "push", "--to", "dc/web", "-f", envFile, "--namespace", "shop"}, test.args...)
+			result := h.Run("", args...)
+			if result.ExitCode != 0 {
+				t.Fatalf("env push failed:\n%s", result)
+			}
+			if updated := updatedWorkload(h); updated != test.updated {
+				t.Errorf("workload updated = %t, want %t:\n%s", updated, test.updated, result)
+			}
+			if env := deploymentConfigEnv(t, h); !reflect.DeepEqual(env, test.expected) {
+				t.Errorf("env = %q, want %q", env, test.expected)
+			}
+		})
+	}
+}
+
+func TestEnvPushAsSecret(t *testing.T) {
+	encode := func(env map[string]string) map[string]interface{} {
+		data := map[string]interface{}{}
+		for name, value := range env {
+			data[name] = base64.StdEncoding.EncodeToString([]byte(value))
+		}
+		return data
+	}
+	secret := func(env map[string]string) map[string]interface{} {
+		return map[string]interface{}{"metadata": map[string]interface{}{"name": "web-env"}, "type": "Opaque", "data": encode(env)}
+	}
+
+	tests := []struct {
+		name       string
+		secrets    []map[string]interface{}
+		referenced bool
+		args       []string
+		expected   map[string]string
+	}{
+		{
+			name:     "created",
+			secrets:  []map[string]interface{}{},
+			expected: map[string]string{"MODE": "release"},
+		},
+		{
+			name:     "updated",
+			secrets:  []map[string]interface{}{secret(map[string]string{"MODE": "debug", "WORKERS": "4"})},
+			expected: map[string]string{"MODE": "release", "WORKERS": "4"},
+		},
+		{
+			name:       "updated and referenced",
+			secrets:    []map[string]interface{}{secret(map[string]string{"MODE": "debug", "WORKERS": "4"})},
+			referenced: true,
+			args:       []string{"--prune"},
+			expected:   map[string]string{"MODE": "release"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := clitest.New(t)
+			h.Server.Objects[deploymentConfigsKey] = []map[string]interface{}{deploymentConfig(map[string]string{"DEBUG": "1"})}
+			h.Server.Objects[secretsKey] = test.secrets
+			if test.referenced {
+				firstContainer(t, h)["envFrom"] = []interface{}{map[string]interface{}{"secretRef": map[string]interface{}{"name": "web-env"}}}
+			}
+			envFile := writeEnvFile(t, "MODE=release\n")
+
+			args := append([]string{"paas", "env", "push", "--to", "dc/web", "-f", envFile, "--namespace", "shop", "--as-secret", "web-env"}, test.args...)
+			result := h.Run("", args...)
+			if result.ExitCode != 0 {
+				t.Fatalf("env push failed:\n%s", result)
+			}
+			if strings.Contains(result.Stdout, "release") {
+				t.Errorf("secret values are previewed:\n%s", result)
+			}
+
+			secrets := h.Server.Objects[secretsKey]
+			if len(secrets) != 1 {
+				t.Fatalf("secrets = %v, want secret web-env", secrets)
+			}
+			if data := secrets[0]["data"]; !reflect.DeepEqual(data, encode(test.expected)) {
+				t.Errorf("secret data = %v, want %v", data, encode(test.expected))
+			}
+
+			if updated := updatedWorkload(h); updated == test.referenced {
+				t.Errorf("workload updated = %t, want %t:\n%s", updated, !test.referenced, result)
+			}
+			expectedEnvFrom := []interface{}{map[string]interface{}{"secretRef": map[string]interface{}{"name": "web-env"}}}
+			if envFrom := firstContainer(t, h)["envFrom"]; !reflect.DeepEqual(envFrom, expectedEnvFrom) {
+				t.Errorf("envFrom = %v, want secret web-env", envFrom)
+			}
+			if env := deploymentConfigEnv(t, h); !reflect.DeepEqual(env, map[string]string{"DEBUG": "1"}) {
+				t.Errorf("env = %q, want variables set by value to be kept", env)
+			}
+		})
+	}
+}
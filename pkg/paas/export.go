@@ -0,0 +1,476 @@
+package paas
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/arvancloud/cli/pkg/options"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+const (
+	bundleApiVersion   = "v1"
+	bundleInfoFileName = "bundle.yaml"
+
+	lastAppliedAnnotation     = "kubectl.kubernetes.io/last-applied-configuration"
+	hostGeneratedAnnotation   = "openshift.io/host.generated"
+	serviceAccountAnnotation  = "kubernetes.io/service-account.name"
+	imageDigestSeparator      = "@sha256:"
+	defaultBundleFileNameTmpl = "%s.tgz"
+)
+
+var (
+	exportLong = `
+    Export resources of a project as a portable bundle
+
+    Configmaps, secrets, persistent volume claims, image streams, build configs, deployment configs,
+    deployments, stateful sets, cron jobs, services and routes of the project are written to a gzipped
+    tar, one file per kind. Fields generated by the cluster such as status, uids, cluster IPs and
+    generated route hosts are dropped, along with objects owned by other objects and secrets of
+    service accounts. Contents of persistent volumes are not exported.`
+
+	restoreLong = `
+    Restore resources of a bundle exported by "arvan paas export"
+
+    Resources are applied to the project in dependency order, e.g secrets and configmaps before
+    deployments and services before routes. The project should already exist; create it by
+    "arvan paas new-project" and switch region first to restore the bundle in another region.`
+)
+
+// bundleKind is a kind of resources exported to bundles, served in path of a namespace.
+type bundleKind struct {
+	resource   string
+	apiVersion string
+	kind       string
+	path       string
+}
+
+// bundleKinds are kinds of resources exported to bundles, in dependency order.
+var bundleKinds = []bundleKind{
+	{"configmaps", "v1", "ConfigMap", "api/v1/namespaces/%s/configmaps"},
+	{"secrets", "v1", "Secret", "api/v1/namespaces/%s/secrets"},
+	{"persistentvolumeclaims", "v1", "PersistentVolumeClaim", "api/v1/namespaces/%s/persistentvolumeclaims"},
+	{"imagestreams", "image.openshift.io/v1", "ImageStream", "apis/image.openshift.io/v1/namespaces/%s/imagestreams"},
+	{"buildconfigs", "build.openshift.io/v1", "BuildConfig", "apis/build.openshift.io/v1/namespaces/%s/buildconfigs"},
+	{"deploymentconfigs", "apps.openshift.io/v1", "DeploymentConfig", deploymentConfigsPath},
+	{"deployments", "apps/v1", "Deployment", deploymentsPath},
+	{"statefulsets", "apps/v1", "StatefulSet", "apis/apps/v1/namespaces/%s/statefulsets"},
+	{"cronjobs", "batch/v1beta1", "CronJob", "apis/batch/v1beta1/namespaces/%s/cronjobs"},
+	{"services", "v1", "Service", "api/v1/namespaces/%s/services"},
+	{"routes", "route.openshift.io/v1", "Route", routesPath},
+}
+
+// generatedConfigMaps are configmaps created by the cluster in every project
+var generatedConfigMaps = map[string]bool{
+	"kube-root-ca.crt":         true,
+	"openshift-service-ca.crt": true,
+}
+
+// generatedAnnotationPrefixes are prefixes of annotations set by the cluster
+var generatedAnnotationPrefixes = []string{
+	lastAppliedAnnotation,
+	"deployment.kubernetes.io/",
+	"pv.kubernetes.io/",
+	"volume.beta.kubernetes.io/",
+	"volume.kubernetes.io/",
+	"openshift.io/build.start-policy",
+	"openshift.io/image.dockerRepositoryCheck",
+}
+
+// BundleInfo describes a bundle exported by "arvan paas export".
+type BundleInfo struct {
+	ApiVersion string    `yaml:"apiVersion"`
+	Project    string    `yaml:"project"`
+	Region     string    `yaml:"region"`
+	ExportedAt time.Time `yaml:"exportedAt"`
+}
+
+// exportOptions selects resources of a project exported by getProjectObjects.
+type exportOptions struct {
+	includeSecrets    bool
+	stripImageDigests bool
+}
+
+// objectList is a list of objects served by paas api.
+type objectList struct {
+	Items []object `json:"items"`
+}
+
+// NewCmdExport returns new cobra commad exporting resources of a project as a bundle.
+func NewCmdExport(o *options.Options) *cobra.Command {
+	var output string
+	var excludeSecrets, stripImageDigests bool
+	cmd := &cobra.Command{
+		Use:   "export PROJECT",
+		Short: "Export resources of a project as a portable bundle",
+		Long:  exportLong,
+		Args:  cobra.ExactArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			project := args[0]
+			if len(output) == 0 {
+				output = fmt.Sprintf(defaultBundleFileNameTmpl, project)
+			}
+
+			objects, err := getProjectObjects(o, getArvanPaasServerBase(o.Config), project, exportOptions{
+				includeSecrets:    !excludeSecrets,
+				stripImageDigests: stripImageDigests,
+			})
+			o.CheckErr(err)
+
+			info := BundleInfo{
+				ApiVersion: bundleApiVersion,
+				Project:    project,
+				Region:     getCurrentRegion(o),
+				ExportedAt: o.Clock.Now().UTC(),
+			}
+			file, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+			o.CheckErr(err)
+			err = writeBundle(file, info, objects)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			o.CheckErr(err)
+
+			count := 0
+			for _, kindObjects := range objects {
+				count += len(kindObjects)
+			}
+			fmt.Fprintf(o.Out, "Exported %d resources of project %q to %s.\n", count, project, output)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Path of the bundle, PROJECT.tgz by default")
+	cmd.Flags().BoolVar(&excludeSecrets, "exclude-secrets", false, "Do not export secrets")
+	cmd.Flags().BoolVar(&stripImageDigests, "strip-image-digests", false, "Replace images pinned by digest with their latest tag")
+
+	return cmd
+}
+
+// NewCmdRestore returns new cobra commad restoring resources of a bundle to a project.
+func NewCmdRestore(o *options.Options) *cobra.Command {
+	var project string
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "restore BUNDLE",
+		Short: "Restore resources of a bundle to a project",
+		Long:  restoreLong,
+		Args:  cobra.ExactArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			file, err := os.Open(args[0])
+			o.CheckErr(err)
+			info, data, err := readBundle(file)
+			file.Close()
+			o.CheckErr(err)
+
+			if len(project) == 0 {
+				project = info.Project
+			}
+			data, err = setObjectsNamespace(data, project)
+			o.CheckErr(err)
+
+			if dryRun {
+				_, err = o.Out.Write(data)
+				o.CheckErr(err)
+				return
+			}
+
+			_, err = getProject(o, project)
+			o.CheckErr(err)
+			o.CheckErr(applyObjects(c.Parent(), data, "--namespace", project))
+			fmt.Fprintf(o.Out, "Bundle of project %q restored to project %q.\n", info.Project, project)
+		},
+	}
+
+	cmd.Flags().StringVar(&project, "project", "", "Project to restore the bundle to, the exported project by default")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print resources of the bundle without applying them")
+
+	return cmd
+}
+
+// getProjectObjects returns resources of project in paas api of serverBase, per kind of bundleKinds.
+// Fields generated by the cluster are dropped. Kinds not served by the api are empty.
+func getProjectObjects(o *options.Options, serverBase, project string, opts exportOptions) ([][]object, error) {
+	namespace := url.PathEscape(project)
+	err := paasRequest(o, http.MethodGet, serverBase+fmt.Sprintf(projectPath, namespace), nil)
+	if utl.KindOf(err) == utl.KindNotFound {
+		return nil, utl.Errorf(utl.KindNotFound, "project %q not found", project)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([][]object, len(bundleKinds))
+	for i, k := range bundleKinds {
+		if k.kind == "Secret" && !opts.includeSecrets {
+			continue
+		}
+
+		var list objectList
+		err = paasRequest(o, http.MethodGet, serverBase+fmt.Sprintf(k.path, namespace), &list)
+		if utl.KindOf(err) == utl.KindNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range list.Items {
+			item["apiVersion"] = k.apiVersion
+			item["kind"] = k.kind
+			if isGeneratedObject(item) {
+				continue
+			}
+			stripGeneratedFields(item, opts.stripImageDigests)
+			objects[i] = append(objects[i], item)
+		}
+		sort.Slice(objects[i], func(a, b int) bool {
+			return objectName(objects[i][a]) < objectName(objects[i][b])
+		})
+	}
+	return objects, nil
+}
+
+// isGeneratedObject reports whether obj is created by the cluster rather than users, e.g pods of deployments
+// or secrets of service accounts.
+func isGeneratedObject(obj object) bool {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	if owners, _ := metadata["ownerReferences"].([]interface{}); len(owners) > 0 {
+		return true
+	}
+	switch obj["kind"] {
+	case "ConfigMap":
+		return generatedConfigMaps[objectName(obj)]
+	case "Secret":
+		annotations, _ := metadata["annotations"].(map[string]interface{})
+		_, ok := annotations[serviceAccountAnnotation]
+		return ok
+	}
+	return false
+}
+
+// stripGeneratedFields drops fields of obj set by the cluster, so it can be applied to another project.
+func stripGeneratedFields(obj object, stripImageDigests bool) {
+	delete(obj, "status")
+
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	for _, field := range []string{"namespace", "uid", "resourceVersion", "generation", "creationTimestamp", "selfLink", "managedFields"} {
+		delete(metadata, field)
+	}
+	if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+		hostGenerated := annotations[hostGeneratedAnnotation] == "true"
+		for name := range annotations {
+			for _, prefix := range generatedAnnotationPrefixes {
+				if strings.HasPrefix(name, prefix) {
+					delete(annotations, name)
+				}
+			}
+		}
+		delete(annotations, hostGeneratedAnnotation)
+		if len(annotations) == 0 {
+			delete(metadata, "annotations")
+		}
+		if spec, ok := obj["spec"].(map[string]interface{}); ok && hostGenerated {
+			delete(spec, "host")
+		}
+	}
+
+	spec, _ := obj["spec"].(map[string]interface{})
+	switch obj["kind"] {
+	case "Service":
+		// headless services keep their "None" cluster IP, any other is allocated by the cluster
+		if spec["clusterIP"] != "None" {
+			delete(spec, "clusterIP")
+		}
+		if clusterIPs, _ := spec["clusterIPs"].([]interface{}); len(clusterIPs) != 1 || clusterIPs[0] != "None" {
+			delete(spec, "clusterIPs")
+		}
+	case "PersistentVolumeClaim":
+		delete(spec, "volumeName")
+	case "DeploymentConfig":
+		triggers, _ := spec["triggers"].([]interface{})
+		for _, trigger := range triggers {
+			params, _ := trigger.(map[string]interface{})["imageChangeParams"].(map[string]interface{})
+			delete(params, "lastTriggeredImage")
+		}
+	}
+
+	if stripImageDigests {
+		stripDigests(obj)
+	}
+}
+
+// stripDigests replaces images of containers pinned by digest e.g "registry/app@sha256:..." with their latest tag.
+func stripDigests(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if image, ok := field.(string); ok && key == "image" {
+				if i := strings.Index(image, imageDigestSeparator); i >= 0 {
+					v[key] = image[:i]
+				}
+				continue
+			}
+			stripDigests(field)
+		}
+	case object:
+		stripDigests(map[string]interface{}(v))
+	case []interface{}:
+		for _, item := range v {
+			stripDigests(item)
+		}
+	}
+}
+
+// getBundleKind returns kind of bundleKinds named kind e.g "Secret".
+func getBundleKind(kind string) (bundleKind, error) {
+	for _, k := range bundleKinds {
+		if k.kind == kind {
+			return k, nil
+		}
+	}
+	return bundleKind{}, fmt.Errorf("unknown resource kind %q", kind)
+}
+
+// objectName returns metadata.name of obj.
+func objectName(obj object) string {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	return name
+}
+
+// bundleFileName returns name of the file of kind i of bundleKinds in bundles, prefixed by its order e.g "01-configmaps.yaml".
+func bundleFileName(i int) string {
+	return fmt.Sprintf("%02d-%s.yaml", i+1, bundleKinds[i].resource)
+}
+
+// writeBundle writes info and objects per kind of bundleKinds to w as a gzipped tar.
+func writeBundle(w io.Writer, info BundleInfo, objects [][]object) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	writeFile := func(name string, data []byte) error {
+		header := &tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), ModTime: info.ExportedAt}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tarWriter.Write(data)
+		return err
+	}
+
+	data, err := yaml.Marshal(info)
+	if err != nil {
+		return err
+	}
+	if err = writeFile(bundleInfoFileName, data); err != nil {
+		return err
+	}
+	for i, kindObjects := range objects {
+		if len(kindObjects) == 0 {
+			continue
+		}
+		data, err := marshalObjects(kindObjects)
+		if err != nil {
+			return err
+		}
+		if err = writeFile(bundleFileName(i), data); err != nil {
+			return err
+		}
+	}
+
+	if err = tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+// readBundle returns info of bundle in r and its resources as a multi-document yaml, in dependency order.
+func readBundle(r io.Reader) (*BundleInfo, []byte, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, utl.Errorf(utl.KindValidation, "invalid bundle: %v", err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+
+	var info *BundleInfo
+	files := map[string][]byte{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, utl.Errorf(utl.KindValidation, "invalid bundle: %v", err)
+		}
+		data, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			return nil, nil, utl.Errorf(utl.KindValidation, "invalid bundle: %v", err)
+		}
+
+		name := path.Clean(header.Name)
+		if name == bundleInfoFileName {
+			info = &BundleInfo{}
+			if err = yaml.Unmarshal(data, info); err != nil {
+				return nil, nil, utl.Errorf(utl.KindValidation, "invalid bundle info: %v", err)
+			}
+			continue
+		}
+		files[name] = data
+	}
+	if info == nil {
+		return nil, nil, utl.Errorf(utl.KindValidation, "invalid bundle: %s is missing", bundleInfoFileName)
+	}
+	if info.ApiVersion != bundleApiVersion {
+		return nil, nil, utl.Errorf(utl.KindValidation, "unsupported bundle apiVersion %q, expected %q", info.ApiVersion, bundleApiVersion)
+	}
+
+	var data []byte
+	for i := range bundleKinds {
+		data = append(data, files[bundleFileName(i)]...)
+	}
+	if len(data) == 0 {
+		return nil, nil, utl.Errorf(utl.KindValidation, "bundle of project %q has no resources", info.Project)
+	}
+	return info, data, nil
+}
+
+// setObjectsNamespace sets namespace of objects in multi-document yaml data, e.g resources of a bundle restored to
+// another project.
+func setObjectsNamespace(data []byte, namespace string) ([]byte, error) {
+	var objects []object
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var obj object
+		err := decoder.Decode(&obj)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, utl.Errorf(utl.KindValidation, "invalid bundle resources: %v", err)
+		}
+		if obj == nil {
+			continue
+		}
+		metadata, ok := obj["metadata"].(map[interface{}]interface{})
+		if !ok {
+			metadata = map[interface{}]interface{}{}
+			obj["metadata"] = metadata
+		}
+		metadata["namespace"] = namespace
+		objects = append(objects, obj)
+	}
+	return marshalObjects(objects)
+}
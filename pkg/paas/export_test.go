@@ -0,0 +1,17 @@
+package paas
+
+// Unexported functions exported to tests of package paas_test.
+var (
+	SprintRegions = sprintRegions
+	SuccessOutput = successOutput
+	ParseEnvFile  = parseEnvFile
+	WriteEnvFile  = writeEnvFile
+	VerifyDomain  = verifyDomain
+)
+
+// Unexported constants exported to tests of package paas_test.
+const (
+	DomainStatusOK       = domainStatusOK
+	DomainStatusNotFound = domainStatusNotFound
+	DomainStatusWrong    = domainStatusWrong
+)
@@ -2,6 +2,8 @@ package paas
 
 import (
 	"io/ioutil"
+	"os"
+	"strings"
 
 	"gopkg.in/yaml.v2"
 )
@@ -43,7 +45,15 @@ type User struct {
 }
 
 type UserInfo struct {
-	Token string `yaml:"token"`
+	Token string      `yaml:"token,omitempty"`
+	Exec  *ExecConfig `yaml:"exec,omitempty"`
+}
+
+// ExecConfig makes kubectl get credentials by running a command, see "arvan paas credential".
+type ExecConfig struct {
+	ApiVersion string   `yaml:"apiVersion"`
+	Command    string   `yaml:"command"`
+	Args       []string `yaml:"args,omitempty"`
 }
 
 func loadCurrentKubeConfig(path string) *KubeConfig {
@@ -60,7 +70,7 @@ func loadCurrentKubeConfig(path string) *KubeConfig {
 	return &kubeConfigData
 }
 
-func populateKubeConfig(arvanPaasServer, arvanHostnamePort, username, token string, projects []string, path string) KubeConfig {
+func populateKubeConfig(arvanPaasServer, arvanHostnamePort, username string, userInfo UserInfo, projects []string, path string) KubeConfig {
 	kubeConfigData := KubeConfig{}
 	kubeConfigData.ApiVersion = "v1"
 	kubeConfigData.Kind = "Config"
@@ -112,9 +122,7 @@ func populateKubeConfig(arvanPaasServer, arvanHostnamePort, username, token stri
 
 	user := User{
 		Name: fullUserName,
-		User: UserInfo{
-			Token: token,
-		},
+		User: userInfo,
 	}
 
 	kubeConfigData.Users = append(kubeConfigData.Users, user)
@@ -142,3 +150,114 @@ func writeKubeConfig(kubeConfig KubeConfig, path string) error {
 	}
 	return nil
 }
+
+// removeKubeConfigCluster removes cluster of arvanHostnamePort along with its users and contexts from kubeConfig.
+// If arvanHostnamePort is empty, all clusters, users and contexts are removed.
+func removeKubeConfigCluster(kubeConfig KubeConfig, arvanHostnamePort string) KubeConfig {
+	matches := func(cluster string) bool {
+		return len(arvanHostnamePort) == 0 || cluster == arvanHostnamePort
+	}
+
+	var clusters []KubeCluster
+	for _, cluster := range kubeConfig.Clusters {
+		if !matches(cluster.Name) {
+			clusters = append(clusters, cluster)
+		}
+	}
+
+	var contexts []KubeContext
+	for _, context := range kubeConfig.Contexts {
+		if !matches(context.Context.Cluster) {
+			contexts = append(contexts, context)
+		}
+	}
+
+	var users []User
+	for _, user := range kubeConfig.Users {
+		if len(arvanHostnamePort) > 0 && !strings.HasSuffix(user.Name, "/"+arvanHostnamePort) {
+			users = append(users, user)
+		}
+	}
+
+	kubeConfig.Clusters = clusters
+	kubeConfig.Contexts = contexts
+	kubeConfig.Users = users
+	if !currentContextExistsAndValid(kubeConfig.CurrentContext, contexts) {
+		kubeConfig.CurrentContext = ""
+		if len(contexts) > 0 {
+			kubeConfig.CurrentContext = contexts[0].Name
+		}
+	}
+	return kubeConfig
+}
+
+// removeKubeConfig removes users and contexts of arvanHostnamePort from kubeconfig in path.
+// If arvanHostnamePort is empty or nothing remains, kubeconfig is removed.
+func removeKubeConfig(path, arvanHostnamePort string) error {
+	currentKubeConfig := loadCurrentKubeConfig(path)
+	if currentKubeConfig == nil {
+		return nil
+	}
+	kubeConfig := removeKubeConfigCluster(*currentKubeConfig, arvanHostnamePort)
+	if len(kubeConfig.Clusters) == 0 && len(kubeConfig.Users) == 0 {
+		err := os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return writeKubeConfig(kubeConfig, path)
+}
+
+// removeKubeConfigNamespace removes contexts of namespace in cluster of arvanHostnamePort from kubeconfig in path.
+func removeKubeConfigNamespace(path, arvanHostnamePort, namespace string) error {
+	currentKubeConfig := loadCurrentKubeConfig(path)
+	if currentKubeConfig == nil {
+		return nil
+	}
+	kubeConfig := *currentKubeConfig
+
+	var contexts []KubeContext
+	for _, context := range kubeConfig.Contexts {
+		if context.Context.Cluster != arvanHostnamePort || context.Context.Namespace == nil || *context.Context.Namespace != namespace {
+			contexts = append(contexts, context)
+		}
+	}
+	kubeConfig.Contexts = contexts
+	if !currentContextExistsAndValid(kubeConfig.CurrentContext, contexts) {
+		kubeConfig.CurrentContext = ""
+		if len(contexts) > 0 {
+			kubeConfig.CurrentContext = contexts[0].Name
+		}
+	}
+	return writeKubeConfig(kubeConfig, path)
+}
+
+// replaceKubeConfigToken replaces oldToken of users in kubeconfig in path with newToken.
+func replaceKubeConfigToken(path, oldToken, newToken string) error {
+	currentKubeConfig := loadCurrentKubeConfig(path)
+	if currentKubeConfig == nil {
+		return nil
+	}
+	kubeConfig := *currentKubeConfig
+	for i := range kubeConfig.Users {
+		if kubeConfig.Users[i].User.Token == oldToken {
+			kubeConfig.Users[i].User.Token = newToken
+		}
+	}
+	return writeKubeConfig(kubeConfig, path)
+}
+
+// currentNamespace returns namespace of current context of kubeconfig in path, or empty if it's not set.
+func currentNamespace(path string) string {
+	kubeConfig := loadCurrentKubeConfig(path)
+	if kubeConfig == nil {
+		return ""
+	}
+	for _, context := range kubeConfig.Contexts {
+		if context.Name == kubeConfig.CurrentContext && context.Context.Namespace != nil {
+			return *context.Context.Namespace
+		}
+	}
+	return ""
+}
@@ -0,0 +1,112 @@
+package paas_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/arvancloud/cli/pkg/cli/clitest"
+	"github.com/arvancloud/cli/pkg/paas"
+)
+
+// loadKubeConfig loads paas kubeconfig saved in home directory of h.
+func loadKubeConfig(t *testing.T, h *clitest.Harness) paas.KubeConfig {
+	t.Helper()
+	data, err := ioutil.ReadFile(filepath.Join(h.HomeDir, "paasconfig"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kubeConfig paas.KubeConfig
+	if err := yaml.Unmarshal(data, &kubeConfig); err != nil {
+		t.Fatal(err)
+	}
+	return kubeConfig
+}
+
+func contextNamespaces(kubeConfig paas.KubeConfig) map[string]string {
+	namespaces := map[string]string{}
+	for _, context := range kubeConfig.Contexts {
+		namespace := ""
+		if context.Context.Namespace != nil {
+			namespace = *context.Context.Namespace
+		}
+		namespaces[context.Name] = namespace
+	}
+	return namespaces
+}
+
+func TestSyncKubeConfig(t *testing.T) {
+	h := clitest.New(t)
+	h.Server.Projects["at1"] = []string{"web", "api"}
+
+	result := h.Run("", "paas", "region", "current")
+	if result.ExitCode != 0 {
+		t.Fatalf("paas command failed:\n%s", result)
+	}
+
+	kubeConfig := loadKubeConfig(t, h)
+	if len(kubeConfig.Clusters) != 1 || kubeConfig.Clusters[0].Cluster.Server != h.Config.GetServer()+"/o/" {
+		t.Errorf("clusters = %+v, want a single cluster of %s/o/", kubeConfig.Clusters, h.Config.GetServer())
+	}
+	cluster := kubeConfig.Clusters[0].Name
+
+	namespaces := contextNamespaces(kubeConfig)
+	expected := map[string]string{
+		"web/" + cluster + "/jane": "web",
+		"api/" + cluster + "/jane": "api",
+	}
+	if len(namespaces) != len(expected) {
+		t.Errorf("contexts = %v, want %v", namespaces, expected)
+	}
+	for name, namespace := range expected {
+		if namespaces[name] != namespace {
+			t.Errorf("namespace of context %q = %q, want %q", name, namespaces[name], namespace)
+		}
+	}
+	if kubeConfig.CurrentContext != "web/"+cluster+"/jane" {
+		t.Errorf("current context = %q, want context of first project", kubeConfig.CurrentContext)
+	}
+
+	if len(kubeConfig.Users) != 1 || kubeConfig.Users[0].Name != "jane/"+cluster {
+		t.Fatalf("users = %+v, want a single user jane/%s", kubeConfig.Users, cluster)
+	}
+	if kubeConfig.Users[0].User.Token != h.Config.GetToken() {
+		t.Errorf("user token = %q, want token of config", kubeConfig.Users[0].User.Token)
+	}
+}
+
+func TestSyncKubeConfigKeepsCurrentContext(t *testing.T) {
+	h := clitest.New(t)
+	h.Server.Projects["at1"] = []string{"web", "api", "db"}
+
+	if result := h.Run("", "paas", "region", "current"); result.ExitCode != 0 {
+		t.Fatalf("paas command failed:\n%s", result)
+	}
+	kubeConfig := loadKubeConfig(t, h)
+	cluster := kubeConfig.Clusters[0].Name
+
+	// switch project as "arvan paas project api" does
+	kubeConfig.CurrentContext = "api/" + cluster + "/jane"
+	data, err := yaml.Marshal(kubeConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(h.HomeDir, "paasconfig"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	h.Server.Projects["at1"] = []string{"web", "api"}
+	if result := h.Run("", "paas", "region", "current"); result.ExitCode != 0 {
+		t.Fatalf("paas command failed:\n%s", result)
+	}
+
+	kubeConfig = loadKubeConfig(t, h)
+	if kubeConfig.CurrentContext != "api/"+cluster+"/jane" {
+		t.Errorf("current context = %q, want context of api project to be kept", kubeConfig.CurrentContext)
+	}
+	if _, ok := contextNamespaces(kubeConfig)["db/"+cluster+"/jane"]; ok {
+		t.Errorf("context of removed project db was kept")
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/arvancloud/cli/pkg/api"
 	"github.com/arvancloud/cli/pkg/config"
+	"github.com/arvancloud/cli/pkg/options"
 	"github.com/arvancloud/cli/pkg/utl"
 )
 
@@ -20,104 +21,218 @@ var (
     Log in to Arvan API and save login for subsequent use
 
     First-time users of the client should run this command to connect to a Arvan API,
-    establish an authenticated session, and save connection to the configuration file.`
+    establish an authenticated session, and save connection to the configuration file.
+
+    Use --web to approve login in a web browser instead of entering an API key, or
+    --service-account-token to log in using token of an OpenShift service account.`
 
 	SwitchRegionLong = `
 	Switch region to connect to different zones.
+
+	Without subcommand, select region from a menu. Use "list", "current" and "use" subcommands in scripts.
 	`
 )
 
 // NewCmdLogin returns new cobra commad enables user to login to arvan servers
-func NewCmdLogin(in io.Reader, out, errout io.Writer) *cobra.Command {
+func NewCmdLogin(o *options.Options) *cobra.Command {
+	var regionName, serviceAccountToken, kubeConfigAuth string
+	var web bool
 	// Main command
 	cmd := &cobra.Command{
 		Use:   "login",
 		Short: "Log in to Arvan server",
 		Long:  loginLong,
 		Run: func(c *cobra.Command, args []string) {
-			explainOut := term.NewResponsiveWriter(out)
+			explainOut := term.NewResponsiveWriter(o.Out)
 			c.SetOutput(explainOut)
 
-			region, err := getSelectedRegion(in, explainOut)
-			utl.CheckErr(err)
-			apiKey := getApiKey(in, explainOut)
+			if web && len(serviceAccountToken) > 0 {
+				o.CheckErr(utl.Errorf(utl.KindValidation, "--web and --service-account-token can not be used together"))
+			}
+			if len(kubeConfigAuth) > 0 {
+				o.CheckErr(utl.NewError(utl.KindValidation, config.ValidateKubeConfigAuth(kubeConfigAuth)))
+			}
+
+			region, err := getLoginRegion(o, regionName, explainOut)
+			o.CheckErr(err)
+
+			var apiKey string
+			if !web && len(serviceAccountToken) == 0 {
+				apiKey, err = getApiKey(o.Config, o.In, explainOut)
+				o.CheckErr(err)
+			}
 
-			_, _ = config.LoadConfigFile()
+			_, _ = o.Config.Load()
 
-			arvanConfig := config.GetConfigInfo()
+			arvanConfig := o.Config
 
-			tempApiKey := arvanConfig.GetApiKey()
+			previousConfig := *arvanConfig
 
 			arvanConfig.Initiate(apiKey, *region)
 
-			utl.CheckErr(arvanConfig.Complete())
+			o.CheckErr(arvanConfig.Complete())
+
+			if len(kubeConfigAuth) > 0 {
+				o.CheckErr(arvanConfig.SetKubeConfigAuth(kubeConfigAuth))
+			}
+
+			switch {
+			case web:
+				token, err := webLogin(o, explainOut)
+				if err != nil {
+					*arvanConfig = previousConfig
+				}
+				o.CheckErr(err)
+				arvanConfig.SetBearerToken(token.AccessToken, token.RefreshToken, token.Expiry(o.Clock.Now()))
+			case len(serviceAccountToken) > 0:
+				arvanConfig.SetServiceAccountToken(serviceAccountToken)
+			}
 
 			_, err = arvanConfig.SaveConfig()
-			utl.CheckErr(err)
+			o.CheckErr(err)
 
-			isAuthorized, authErr := isAuthorized(apiKey)
-			if !isAuthorized {
-				arvanConfig.Initiate(tempApiKey, *region)
+			authErr := validateCredentials(o)
+			if authErr != nil {
+				// restore previous credentials and server as they were, Initiate would clear a previous api key
+				*arvanConfig = previousConfig
 				_, err = arvanConfig.SaveConfig()
-				utl.CheckErr(err)
+				o.CheckErr(err)
 			}
-			utl.CheckErr(authErr)
+			o.CheckErr(authErr)
 
 			if c != nil {
-				err = prepareConfig(c)
+				err = prepareConfig(o, c)
 			}
-			utl.CheckErr(err)
+			o.CheckErr(err)
 			fmt.Fprintf(explainOut, "Valid Authorization credentials. Logged in successfully!\n")
 		},
 	}
 
+	cmd.Flags().StringVar(&regionName, "region", "", "Region to log in to, e.g ir-thr-at1, or 'auto' to choose the one with the lowest latency. Prompts if not set")
+	cmd.Flags().BoolVar(&web, "web", false, "Log in using a web browser instead of an API key")
+	cmd.Flags().StringVar(&kubeConfigAuth, "kubeconfig-auth", "", "How users of paas kubeconfig authorize. One of: token, exec. 'exec' runs arvan to get the current credentials on each use")
+	cmd.Flags().StringVar(&serviceAccountToken, "service-account-token", "", "Log in using token of an OpenShift service account, e.g in CI. Only PaaS commands are available")
+
 	return cmd
 }
 
-// NewCmdLogin returns new cobra commad enables user to switch region
-func NewCmdSwitchRegion(in io.Reader, out, errout io.Writer) *cobra.Command {
+// webLogin authorizes arvan cli using device authorization flow, asking user to approve it in a web browser.
+func webLogin(o *options.Options, writer io.Writer) (*api.Token, error) {
+	deviceCode, err := o.Client.RequestDeviceCode()
+	if err != nil {
+		return nil, err
+	}
+
+	verificationURI := deviceCode.VerificationURIComplete
+	if len(verificationURI) == 0 {
+		verificationURI = deviceCode.VerificationURI
+	}
+	fmt.Fprintf(writer, "Open %s in your browser and enter code %s\n", verificationURI, deviceCode.UserCode)
+	fmt.Fprintf(writer, "Waiting for approval...\n")
+
+	return o.Client.PollDeviceToken(deviceCode, o.Clock)
+}
+
+// validateCredentials makes sure credentials of config are valid. Service account tokens are validated
+// against paas api since they are not valid for arvan api.
+func validateCredentials(o *options.Options) error {
+	if o.Config.GetAuthType() != config.AuthTypeServiceAccount {
+		_, err := isAuthorized(o.Client, getArvanAuthorization(o.Config))
+		return err
+	}
+
+	// #TODO do not use InsecureSkipVerify
+	o.Client.InsecureSkipTLSVerify()
+	_, httpStatusCode, err := whoAmI(o)
+	if err != nil {
+		return whoAmIError(httpStatusCode, err)
+	}
+	return nil
+}
+
+// getLoginRegion returns zone named regionName, nearest zone if it's "auto" or asks user to select one if it's empty.
+func getLoginRegion(o *options.Options, regionName string, writer io.Writer) (*config.Zone, error) {
+	if len(regionName) == 0 {
+		return getSelectedRegion(o, writer)
+	}
+
+	zones, err := getZones(o)
+	if err != nil {
+		return nil, err
+	}
+
+	if regionName == regionAuto {
+		zone, err := getNearestZone(o, zones)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(writer, "Nearest region: %s\n", zoneFullName(*zone))
+		return zone, nil
+	}
+
+	zone, err := findZone(zones, regionName)
+	if err != nil {
+		return nil, err
+	}
+	return zone, validateZoneUp(*zone)
+}
+
+// NewCmdSwitchRegion returns new cobra commad enables user to switch region
+func NewCmdSwitchRegion(o *options.Options) *cobra.Command {
 	// Main command
 	cmd := &cobra.Command{
 		Use:   "region",
 		Short: "Switch region",
 		Long:  SwitchRegionLong,
 		Run: func(c *cobra.Command, args []string) {
-			explainOut := term.NewResponsiveWriter(out)
+			explainOut := term.NewResponsiveWriter(o.Out)
 			c.SetOutput(explainOut)
 
-			region, err := getSelectedRegion(in, explainOut)
-			utl.CheckErr(err)
+			region, err := getSelectedRegion(o, explainOut)
+			o.CheckErr(err)
 
-			_, _ = config.LoadConfigFile()
+			err = switchRegion(o, c, *region)
+			o.CheckErr(err)
 
-			arvanConfig := config.GetConfigInfo()
+			fmt.Fprintf(explainOut, "Region Switched successfully.\n")
+		},
+	}
 
-			arvanConfig.Initiate(arvanConfig.GetApiKey(), *region)
+	cmd.AddCommand(newCmdRegionList(o))
+	cmd.AddCommand(newCmdRegionCurrent(o))
+	cmd.AddCommand(newCmdRegionUse(o))
+	cmd.AddCommand(newCmdRegionPing(o))
 
-			utl.CheckErr(arvanConfig.Complete())
+	return cmd
+}
 
-			_, err = arvanConfig.SaveConfig()
-			utl.CheckErr(err)
+// switchRegion saves zone as current zone and syncs paas kubeconfig with it.
+func switchRegion(o *options.Options, cmd *cobra.Command, zone config.Zone) error {
+	_, _ = o.Config.Load()
 
-			err = prepareConfigSwtichRegion(c)
-			utl.CheckErr(err)
+	arvanConfig := o.Config
 
-			fmt.Fprintf(explainOut, "Region Switched successfully.\n")
-		},
+	arvanConfig.Initiate(arvanConfig.GetApiKey(), zone)
+
+	if err := arvanConfig.Complete(); err != nil {
+		return err
 	}
 
-	return cmd
+	if _, err := arvanConfig.SaveConfig(); err != nil {
+		return err
+	}
+
+	return prepareConfigSwtichRegion(o, cmd)
 }
 
-func isAuthorized(apiKey string) (bool, error) {
-	if _, err := api.GetUserInfo(apiKey); err != nil {
+func isAuthorized(client *api.Client, apiKey string) (bool, error) {
+	if _, err := client.GetUserInfo(apiKey); err != nil {
 		return false, err
 	}
 	return true, nil
 }
 
-func getApiKey(in io.Reader, writer io.Writer) string {
-	arvanConfig := config.GetConfigInfo()
+func getApiKey(arvanConfig *config.ConfigInfo, in io.Reader, writer io.Writer) (string, error) {
 
 	inputExplain := "Enter arvan API token: "
 	defaultVal := arvanConfig.GetApiKey()
@@ -138,19 +253,19 @@ func apiKeyValidator(input string) (bool, error) {
 }
 
 // getSelectedRegion #TODO implement getSelectedRegion
-func getSelectedRegion(in io.Reader, writer io.Writer) (*config.Zone, error) {
-	regions, err := api.GetZones()
+func getSelectedRegion(o *options.Options, writer io.Writer) (*config.Zone, error) {
+	regions, err := o.Client.GetZones()
 	if err != nil {
 		return nil, err
 	}
 	if len(regions.Zones) < 1 {
-		return nil, errors.New("invalid region info")
+		return nil, utl.NewError(utl.KindServer, errors.New("invalid region info"))
 	}
 
 	upZones, downZones := getUpAndDownZones(regions.Zones)
 
 	if len(upZones) < 1 {
-		return nil, errors.New("no active region available")
+		return nil, utl.NewError(utl.KindServer, errors.New("no active region available"))
 	}
 
 	explain := "Select arvan region:\n"
@@ -171,7 +286,10 @@ func getSelectedRegion(in io.Reader, writer io.Writer) (*config.Zone, error) {
 
 	validator := regionValidator{len(upZones)}
 
-	regionIndex := utl.ReadInput(inputExplain, defaultVal, writer, in, validator.validate)
+	regionIndex, err := utl.ReadInput(inputExplain, defaultVal, writer, o.In, validator.validate)
+	if err != nil {
+		return nil, err
+	}
 	intIndex, _ := strconv.Atoi(regionIndex)
 
 	return &upZones[intIndex-1], nil
@@ -223,7 +341,7 @@ func sprintRegions(activeZones, inactiveRegions []config.Zone) string {
 func getUpAndDownZones(zones []config.Zone) ([]config.Zone, []config.Zone) {
 	var activeZones, inactiveZones []config.Zone
 	for i := 0; i < len(zones); i++ {
-		if zones[i].Status == "UP" {
+		if zones[i].Status == zoneStatusUp {
 			activeZones = append(activeZones, zones[i])
 		} else {
 			inactiveZones = append(inactiveZones, zones[i])
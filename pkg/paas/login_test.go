@@ -0,0 +1,261 @@
+package paas_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvancloud/cli/pkg/api/apitest"
+	"github.com/arvancloud/cli/pkg/cli/clitest"
+	"github.com/arvancloud/cli/pkg/config"
+	"github.com/arvancloud/cli/pkg/paas"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+// savedConfig loads config saved in home directory of h.
+func savedConfig(t *testing.T, h *clitest.Harness) *config.ConfigInfo {
+	t.Helper()
+	arvanConfig := config.NewConfigInfo(h.HomeDir)
+	if _, err := arvanConfig.Load(); err != nil {
+		t.Fatal(err)
+	}
+	return arvanConfig
+}
+
+func TestLogin(t *testing.T) {
+	h := clitest.New(t)
+	h.Server.Projects["at1"] = []string{"web"}
+
+	result := h.Run(apitest.DefaultApiKey+"\n", "login")
+	if result.ExitCode != 0 {
+		t.Fatalf("login failed:\n%s", result)
+	}
+	if !strings.Contains(result.Stdout, "Logged in successfully!") {
+		t.Errorf("login did not report success:\n%s", result)
+	}
+
+	arvanConfig := savedConfig(t, h)
+	if arvanConfig.GetApiKey() != apitest.DefaultApiKey {
+		t.Errorf("saved api key = %q, want %q", arvanConfig.GetApiKey(), apitest.DefaultApiKey)
+	}
+	if !strings.HasSuffix(arvanConfig.GetServer(), "/ir-thr-at1") {
+		t.Errorf("saved server = %q, want server of ir-thr-at1", arvanConfig.GetServer())
+	}
+}
+
+func TestLoginRegion(t *testing.T) {
+	h := clitest.New(t)
+	h.Server.AddZone("ir-tbz", "sh1", "UP")
+	h.Server.Projects["sh1"] = []string{"web"}
+
+	result := h.Run(apitest.DefaultApiKey+"\n", "login", "--region", "ir-tbz-sh1")
+	if result.ExitCode != 0 {
+		t.Fatalf("login failed:\n%s", result)
+	}
+	if server := savedConfig(t, h).GetServer(); !strings.HasSuffix(server, "/ir-tbz-sh1") {
+		t.Errorf("saved server = %q, want server of ir-tbz-sh1", server)
+	}
+}
+
+func TestLoginInvalidApiKey(t *testing.T) {
+	h := clitest.New(t)
+	h.Server.AddZone("ir-tbz", "sh1", "UP")
+	h.Server.Projects["at1"] = []string{"web"}
+
+	result := h.Run("Apikey 11111111-1111-1111-1111-111111111111\n", "login", "--region", "ir-tbz-sh1")
+	if result.ExitCode != utl.AuthErrorExitCode {
+		t.Fatalf("exit code = %d, want %d:\n%s", result.ExitCode, utl.AuthErrorExitCode, result)
+	}
+	arvanConfig := savedConfig(t, h)
+	if apiKey := arvanConfig.GetApiKey(); apiKey != apitest.DefaultApiKey {
+		t.Errorf("saved api key = %q, want previous api key to be kept", apiKey)
+	}
+	if server := arvanConfig.GetServer(); !strings.HasSuffix(server, "/ir-thr-at1") {
+		t.Errorf("saved server = %q, want previous server of ir-thr-at1 to be kept", server)
+	}
+}
+
+func TestLoginWeb(t *testing.T) {
+	h := clitest.New(t)
+	h.Server.Projects["at1"] = []string{"web"}
+	h.Server.BearerToken = "token-1"
+	h.Server.RefreshToken = "refresh-1"
+	h.Server.TokenExpiresIn = 3600
+	h.Server.DeviceApproved = true
+
+	result := h.Run("", "login", "--web", "--region", "ir-thr-at1")
+	if result.ExitCode != 0 {
+		t.Fatalf("login failed:\n%s", result)
+	}
+	if !strings.Contains(result.Stdout, apitest.UserCode) {
+		t.Errorf("user code is not printed:\n%s", result)
+	}
+
+	arvanConfig := savedConfig(t, h)
+	if arvanConfig.GetAuthType() != config.AuthTypeBearer || arvanConfig.GetToken() != "token-1" || arvanConfig.GetRefreshToken() != "refresh-1" {
+		t.Errorf("saved credentials = %s %q refreshed by %q, want bearer token-1 refreshed by refresh-1",
+			arvanConfig.GetAuthType(), arvanConfig.GetToken(), arvanConfig.GetRefreshToken())
+	}
+	if len(arvanConfig.GetApiKey()) > 0 {
+		t.Errorf("previous api key is kept along with the bearer token")
+	}
+	if expiry := arvanConfig.GetTokenExpiry(); expiry.IsZero() {
+		t.Errorf("token expiry is not saved")
+	}
+	for _, user := range loadKubeConfig(t, h).Users {
+		if user.User.Token != "token-1" {
+			t.Errorf("token of kubeconfig user %s = %q, want the bearer token", user.Name, user.User.Token)
+		}
+	}
+}
+
+func TestLoginWebExpired(t *testing.T) {
+	h := clitest.New(t)
+	h.Server.BearerToken = "token-1"
+	useSteppingClock(t, h)
+
+	result := h.Run("", "login", "--web", "--region", "ir-thr-at1")
+	if result.ExitCode != utl.AuthErrorExitCode {
+		t.Fatalf("exit code = %d, want %d:\n%s", result.ExitCode, utl.AuthErrorExitCode, result)
+	}
+	if !strings.Contains(result.Stderr, "login request expired") {
+		t.Errorf("expiry of the login request is not reported:\n%s", result)
+	}
+	if apiKey := savedConfig(t, h).GetApiKey(); apiKey != apitest.DefaultApiKey {
+		t.Errorf("saved api key = %q, want previous api key to be kept", apiKey)
+	}
+}
+
+// loginBearer saves a bearer token of h expired a minute ago, refreshed using refresh-1.
+func loginBearer(t *testing.T, h *clitest.Harness) {
+	t.Helper()
+	h.Config.SetBearerToken("expired-token", "refresh-1", time.Now().Add(-time.Minute))
+	if _, err := h.Config.SaveConfig(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRefreshBearerToken(t *testing.T) {
+	h := clitest.New(t)
+	loginBearer(t, h)
+	h.Server.BearerToken = "token-2"
+	h.Server.RefreshToken = "refresh-1"
+	h.Server.TokenExpiresIn = 3600
+
+	result := h.Run("", "whoami")
+	if result.ExitCode != 0 {
+		t.Fatalf("whoami failed:\n%s", result)
+	}
+
+	arvanConfig := savedConfig(t, h)
+	if arvanConfig.GetToken() != "token-2" || arvanConfig.GetRefreshToken() != "refresh-1" {
+		t.Errorf("saved token = %q refreshed by %q, want refreshed token-2", arvanConfig.GetToken(), arvanConfig.GetRefreshToken())
+	}
+	if expiry := arvanConfig.GetTokenExpiry(); !expiry.After(time.Now()) {
+		t.Errorf("saved token expiry = %v, want expiry of the refreshed token", expiry)
+	}
+}
+
+func TestRefreshBearerTokenDenied(t *testing.T) {
+	h := clitest.New(t)
+	loginBearer(t, h)
+	h.Server.BearerToken = "token-2"
+
+	result := h.Run("", "whoami")
+	if result.ExitCode != utl.AuthErrorExitCode {
+		t.Fatalf("exit code = %d, want %d:\n%s", result.ExitCode, utl.AuthErrorExitCode, result)
+	}
+	if !strings.Contains(result.Stderr, "arvan login --web") {
+		t.Errorf("error does not suggest logging in again:\n%s", result)
+	}
+	if token := savedConfig(t, h).GetToken(); token != "expired-token" {
+		t.Errorf("saved token = %q, want expired token to be kept", token)
+	}
+}
+
+func TestSwitchRegion(t *testing.T) {
+	h := clitest.New(t)
+	h.Server.AddZone("ir-tbz", "sh1", "UP")
+	h.Server.Projects["at1"] = []string{"web"}
+	h.Server.Projects["sh1"] = []string{"api"}
+
+	result := h.Run("2\n", "paas", "region")
+	if result.ExitCode != 0 {
+		t.Fatalf("switching region failed:\n%s", result)
+	}
+	if !strings.Contains(result.Stdout, "Region Switched successfully.") {
+		t.Errorf("switching region did not report success:\n%s", result)
+	}
+	if server := savedConfig(t, h).GetServer(); !strings.HasSuffix(server, "/ir-tbz-sh1") {
+		t.Errorf("saved server = %q, want server of ir-tbz-sh1", server)
+	}
+}
+
+func TestSwitchRegionUse(t *testing.T) {
+	tests := []struct {
+		name     string
+		region   string
+		exitCode int
+		server   string
+	}{
+		{name: "full name", region: "ir-tbz-sh1", server: "/ir-tbz-sh1"},
+		{name: "zone name", region: "sh1", server: "/ir-tbz-sh1"},
+		{name: "down", region: "ir-tbz-sh2", exitCode: utl.ValidationErrorExitCode, server: "/ir-thr-at1"},
+		{name: "unknown", region: "ir-tbz-sh3", exitCode: utl.NotFoundErrorExitCode, server: "/ir-thr-at1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := clitest.New(t)
+			h.Server.AddZone("ir-tbz", "sh1", "UP")
+			h.Server.AddZone("ir-tbz", "sh2", "DOWN")
+			h.Server.Projects["at1"] = []string{"web"}
+			h.Server.Projects["sh1"] = []string{"api"}
+
+			result := h.Run("", "paas", "region", "use", test.region)
+			if result.ExitCode != test.exitCode {
+				t.Fatalf("exit code = %d, want %d:\n%s", result.ExitCode, test.exitCode, result)
+			}
+			if server := savedConfig(t, h).GetServer(); !strings.HasSuffix(server, test.server) {
+				t.Errorf("saved server = %q, want suffix %q", server, test.server)
+			}
+		})
+	}
+}
+
+func TestSprintRegions(t *testing.T) {
+	zone := func(regionName, name, release string, isDefault bool) config.Zone {
+		return config.Zone{RegionName: regionName, Name: name, Release: release, Default: isDefault}
+	}
+
+	tests := []struct {
+		golden    string
+		upZones   []config.Zone
+		downZones []config.Zone
+	}{
+		{
+			golden: "sprint-regions",
+			upZones: []config.Zone{
+				zone("ir-thr", "at1", "STABLE", false),
+				zone("ir-tbz", "sh1", "BETA", true),
+				zone("ir-thr", "ba1", "STABLE", false),
+			},
+			downZones: []config.Zone{
+				zone("ir-thr", "at2", "STABLE", false),
+			},
+		},
+		{
+			golden: "sprint-regions-all-up",
+			upZones: []config.Zone{
+				zone("ir-thr", "at1", "STABLE", true),
+				zone("ir-tbz", "sh1", "STABLE", false),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.golden, func(t *testing.T) {
+			clitest.AssertGolden(t, test.golden, paas.SprintRegions(test.upZones, test.downZones))
+		})
+	}
+}
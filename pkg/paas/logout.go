@@ -0,0 +1,49 @@
+package paas
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arvancloud/cli/pkg/options"
+)
+
+var (
+	logoutLong = `
+    Log out of Arvan API
+
+    Removes the API key saved by "arvan login" and the users and contexts of current zone
+    from the generated paas kubeconfig. Use --all-profiles to also forget the selected zone
+    and remove credentials of every zone.`
+)
+
+// NewCmdLogout returns new cobra commad enables user to remove saved credentials
+func NewCmdLogout(o *options.Options) *cobra.Command {
+	var allProfiles bool
+	cmd := &cobra.Command{
+		Use:   "logout",
+		Short: "Log out of Arvan server",
+		Long:  logoutLong,
+		Run: func(c *cobra.Command, args []string) {
+			arvanConfig := o.Config
+			kubeConfigPath := paasConfigPath(arvanConfig)
+
+			if allProfiles {
+				o.CheckErr(removeKubeConfig(kubeConfigPath, ""))
+				o.CheckErr(arvanConfig.RemoveConfig())
+				fmt.Fprintf(o.Out, "Logged out of all zones successfully.\n")
+				return
+			}
+
+			arvanHostnamePort, err := getArvanServerDomainPort(arvanConfig)
+			o.CheckErr(err)
+			o.CheckErr(removeKubeConfig(kubeConfigPath, arvanHostnamePort))
+			o.CheckErr(arvanConfig.ClearCredentials())
+			fmt.Fprintf(o.Out, "Logged out successfully.\n")
+		},
+	}
+
+	cmd.Flags().BoolVar(&allProfiles, "all-profiles", false, "Remove credentials of every zone and the selected zone too")
+
+	return cmd
+}
@@ -0,0 +1,86 @@
+package paas_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/arvancloud/cli/pkg/cli/clitest"
+	"github.com/arvancloud/cli/pkg/paas"
+)
+
+const otherCluster = "other.example.com:443"
+
+// addOtherCluster syncs paas kubeconfig of h and adds a cluster of another server to it.
+func addOtherCluster(t *testing.T, h *clitest.Harness) {
+	t.Helper()
+	if result := h.Run("", "paas", "region", "current"); result.ExitCode != 0 {
+		t.Fatalf("paas command failed:\n%s", result)
+	}
+
+	namespace := "default"
+	kubeConfig := loadKubeConfig(t, h)
+	kubeConfig.Clusters = append(kubeConfig.Clusters, paas.KubeCluster{Name: otherCluster, Cluster: paas.ClusterInfo{Server: "https://" + otherCluster}})
+	kubeConfig.Contexts = append(kubeConfig.Contexts, paas.KubeContext{
+		Name:    "default/" + otherCluster + "/bob",
+		Context: paas.ContextInfo{Cluster: otherCluster, Namespace: &namespace, User: "bob/" + otherCluster},
+	})
+	kubeConfig.Users = append(kubeConfig.Users, paas.User{Name: "bob/" + otherCluster, User: paas.UserInfo{Token: "other-token"}})
+	data, err := yaml.Marshal(kubeConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(h.HomeDir, "paasconfig"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	h := clitest.New(t)
+	h.Server.Projects["at1"] = []string{"web"}
+	addOtherCluster(t, h)
+
+	result := h.Run("", "logout")
+	if result.ExitCode != 0 {
+		t.Fatalf("logout failed:\n%s", result)
+	}
+
+	arvanConfig := savedConfig(t, h)
+	if arvanConfig.HasCredentials() {
+		t.Errorf("credentials are kept after logout")
+	}
+	if arvanConfig.GetServer() != h.Config.GetServer() {
+		t.Errorf("saved server = %q, want selected zone %q to be kept", arvanConfig.GetServer(), h.Config.GetServer())
+	}
+
+	kubeConfig := loadKubeConfig(t, h)
+	if len(kubeConfig.Clusters) != 1 || kubeConfig.Clusters[0].Name != otherCluster {
+		t.Errorf("clusters = %+v, want only %s", kubeConfig.Clusters, otherCluster)
+	}
+	if len(kubeConfig.Users) != 1 || kubeConfig.Users[0].Name != "bob/"+otherCluster {
+		t.Errorf("users = %+v, want only the user of %s", kubeConfig.Users, otherCluster)
+	}
+	if kubeConfig.CurrentContext != "default/"+otherCluster+"/bob" {
+		t.Errorf("current context = %q, want the context of %s", kubeConfig.CurrentContext, otherCluster)
+	}
+}
+
+func TestLogoutAllProfiles(t *testing.T) {
+	h := clitest.New(t)
+	h.Server.Projects["at1"] = []string{"web"}
+	addOtherCluster(t, h)
+
+	result := h.Run("", "logout", "--all-profiles")
+	if result.ExitCode != 0 {
+		t.Fatalf("logout failed:\n%s", result)
+	}
+
+	for _, name := range []string{"config", "paasconfig"} {
+		if _, err := os.Stat(filepath.Join(h.HomeDir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s is kept after logging out of all profiles: %v", name, err)
+		}
+	}
+}
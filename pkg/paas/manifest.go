@@ -0,0 +1,382 @@
+package paas
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+const (
+	defaultManifestFileName = "arvan.yaml"
+	manifestApiVersion      = "v1"
+
+	// appLabel marks objects rendered from a manifest, it's used to prune objects removed from the manifest
+	appLabel = "arvan.ir/app"
+
+	imageTriggerAnnotation = "image.openshift.io/triggers"
+	dependsOnAnnotation    = "arvan.ir/depends-on"
+	builderNamespace       = "openshift"
+
+	// triggeredImagePlaceholder is the image of containers whose image is set by an image trigger
+	triggeredImagePlaceholder = " "
+)
+
+var manifestNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// Manifest declares an application deployed by "arvan paas deploy".
+type Manifest struct {
+	ApiVersion string            `yaml:"apiVersion"`
+	Name       string            `yaml:"name"`
+	Project    string            `yaml:"project,omitempty"`
+	Services   []ManifestService `yaml:"services"`
+}
+
+// ManifestService is a service of a Manifest, either built from a git repository or run from an image.
+type ManifestService struct {
+	Name      string            `yaml:"name"`
+	Build     *ManifestBuild    `yaml:"build,omitempty"`
+	Image     string            `yaml:"image,omitempty"`
+	Replicas  *int              `yaml:"replicas,omitempty"`
+	Port      int               `yaml:"port,omitempty"`
+	Env       map[string]string `yaml:"env,omitempty"`
+	Resources ManifestResources `yaml:"resources,omitempty"`
+	Routes    []ManifestRoute   `yaml:"routes,omitempty"`
+	Volumes   []ManifestVolume  `yaml:"volumes,omitempty"`
+	DependsOn []string          `yaml:"dependsOn,omitempty"`
+}
+
+// ManifestBuild is the source a service is built from.
+type ManifestBuild struct {
+	Git string `yaml:"git,omitempty"`
+	// Binary builds sources uploaded by "arvan paas up" instead of a git repository
+	Binary     bool   `yaml:"binary,omitempty"`
+	Ref        string `yaml:"ref,omitempty"`
+	ContextDir string `yaml:"contextDir,omitempty"`
+	// Builder is the image stream tag building the source e.g python:3.9, ArvanBuilder by default.
+	// The latest tag is used if it has no tag e.g python
+	Builder string `yaml:"builder,omitempty"`
+}
+
+// ManifestResources limits resources of each replica of a service e.g cpu: 500m, memory: 512Mi.
+type ManifestResources struct {
+	CPU    string `yaml:"cpu,omitempty"`
+	Memory string `yaml:"memory,omitempty"`
+}
+
+// ManifestRoute exposes a service on a domain.
+type ManifestRoute struct {
+	Host string `yaml:"host,omitempty"`
+	Path string `yaml:"path,omitempty"`
+	TLS  bool   `yaml:"tls,omitempty"`
+}
+
+// ManifestVolume is a persistent volume mounted to a service.
+type ManifestVolume struct {
+	Name      string `yaml:"name"`
+	MountPath string `yaml:"mountPath"`
+	Size      string `yaml:"size"`
+}
+
+// object is an OpenShift object rendered from a manifest.
+type object map[string]interface{}
+
+// loadManifest reads and validates the manifest in path.
+func loadManifest(path string) (*Manifest, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, utl.NewError(utl.KindValidation, err)
+	}
+	var manifest Manifest
+	if err = yaml.UnmarshalStrict(data, &manifest); err != nil {
+		return nil, utl.Errorf(utl.KindValidation, "invalid manifest %s: %v", path, err)
+	}
+	if err = manifest.validate(); err != nil {
+		return nil, utl.Errorf(utl.KindValidation, "invalid manifest %s: %v", path, err)
+	}
+	return &manifest, nil
+}
+
+func (m *Manifest) validate() error {
+	if m.ApiVersion != manifestApiVersion {
+		return fmt.Errorf("unsupported apiVersion %q, expected %q", m.ApiVersion, manifestApiVersion)
+	}
+	if !manifestNameRegexp.MatchString(m.Name) {
+		return fmt.Errorf("name %q should consist of lower case alphanumeric characters or '-'", m.Name)
+	}
+	if len(m.Services) == 0 {
+		return fmt.Errorf("no services declared")
+	}
+
+	names := map[string]bool{}
+	for _, s := range m.Services {
+		if !manifestNameRegexp.MatchString(s.Name) {
+			return fmt.Errorf("service name %q should consist of lower case alphanumeric characters or '-'", s.Name)
+		}
+		if names[s.Name] {
+			return fmt.Errorf("service %q declared more than once", s.Name)
+		}
+		names[s.Name] = true
+
+		if (s.Build == nil) == (len(s.Image) == 0) {
+			return fmt.Errorf("service %q should declare either build or image", s.Name)
+		}
+		if s.Build != nil && (len(s.Build.Git) == 0) == !s.Build.Binary {
+			return fmt.Errorf("service %q should declare either git repository or binary source of its build", s.Name)
+		}
+		if len(s.Routes) > 0 && s.Port == 0 {
+			return fmt.Errorf("service %q should declare port to be routed", s.Name)
+		}
+		for _, dependency := range s.DependsOn {
+			if !m.hasService(dependency) {
+				return fmt.Errorf("service %q depends on undeclared service %q", s.Name, dependency)
+			}
+		}
+		for _, v := range s.Volumes {
+			if !manifestNameRegexp.MatchString(v.Name) || len(v.MountPath) == 0 || len(v.Size) == 0 {
+				return fmt.Errorf("volume %q of service %q should declare name, mountPath and size", v.Name, s.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Manifest) hasService(name string) bool {
+	for _, s := range m.Services {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// render returns OpenShift objects of the manifest, labeled by its name.
+func (m *Manifest) render() ([]object, error) {
+	var objects []object
+	for _, s := range m.Services {
+		serviceObjects, err := m.renderService(s)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, serviceObjects...)
+	}
+	return objects, nil
+}
+
+func (m *Manifest) renderService(s ManifestService) ([]object, error) {
+	labels := map[string]interface{}{appLabel: m.Name, "app": s.Name}
+	metadata := func(name string) object {
+		return object{"name": name, "labels": labels}
+	}
+
+	var objects []object
+	deploymentMetadata := metadata(s.Name)
+
+	if s.Build != nil {
+		builder := arvanBuilderImage
+		if len(s.Build.Builder) > 0 {
+			builder = imageStreamTag(s.Build.Builder)
+		}
+		source := object{"type": "Binary"}
+		triggers := []object{}
+		if !s.Build.Binary {
+			git := object{"uri": s.Build.Git}
+			if len(s.Build.Ref) > 0 {
+				git["ref"] = s.Build.Ref
+			}
+			source = object{"type": "Git", "git": git}
+			triggers = append(triggers, object{"type": "ConfigChange"})
+		}
+		if len(s.Build.ContextDir) > 0 {
+			source["contextDir"] = s.Build.ContextDir
+		}
+
+		objects = append(objects,
+			object{
+				"apiVersion": "image.openshift.io/v1",
+				"kind":       "ImageStream",
+				"metadata":   metadata(s.Name),
+			},
+			object{
+				"apiVersion": "build.openshift.io/v1",
+				"kind":       "BuildConfig",
+				"metadata":   metadata(s.Name),
+				"spec": object{
+					"source": source,
+					"strategy": object{
+						"type": "Source",
+						"sourceStrategy": object{
+							"from": object{"kind": "ImageStreamTag", "namespace": builderNamespace, "name": builder},
+						},
+					},
+					"output": object{
+						"to": object{"kind": "ImageStreamTag", "name": s.Name + ":latest"},
+					},
+					"triggers": triggers,
+				},
+			})
+
+		trigger, err := json.Marshal([]object{{
+			"from":      object{"kind": "ImageStreamTag", "name": s.Name + ":latest"},
+			"fieldPath": fmt.Sprintf("spec.template.spec.containers[?(@.name==\"%s\")].image", s.Name),
+		}})
+		if err != nil {
+			return nil, err
+		}
+		deploymentMetadata["annotations"] = object{imageTriggerAnnotation: string(trigger)}
+	}
+	if len(s.DependsOn) > 0 {
+		annotations, ok := deploymentMetadata["annotations"].(object)
+		if !ok {
+			annotations = object{}
+			deploymentMetadata["annotations"] = annotations
+		}
+		annotations[dependsOnAnnotation] = strings.Join(s.DependsOn, ",")
+	}
+
+	// image of built services is a placeholder replaced by the image trigger, as oc new-app does. The image is
+	// required, and since the placeholder never changes, applying the manifest again keeps the resolved image.
+	image := s.Image
+	if s.Build != nil {
+		image = triggeredImagePlaceholder
+	}
+	container := object{"name": s.Name, "image": image}
+	if s.Port > 0 {
+		container["ports"] = []object{{"containerPort": s.Port, "protocol": "TCP"}}
+	}
+	if env := renderEnv(s.Env); len(env) > 0 {
+		container["env"] = env
+	}
+	if limits := renderResources(s.Resources); len(limits) > 0 {
+		container["resources"] = object{"limits": limits, "requests": limits}
+	}
+
+	var volumes []object
+	var mounts []object
+	for _, v := range s.Volumes {
+		claimName := s.Name + "-" + v.Name
+		objects = append(objects, object{
+			"apiVersion": "v1",
+			"kind":       "PersistentVolumeClaim",
+			"metadata":   metadata(claimName),
+			"spec": object{
+				"accessModes": []string{"ReadWriteOnce"},
+				"resources":   object{"requests": object{"storage": v.Size}},
+			},
+		})
+		volumes = append(volumes, object{"name": v.Name, "persistentVolumeClaim": object{"claimName": claimName}})
+		mounts = append(mounts, object{"name": v.Name, "mountPath": v.MountPath})
+	}
+	if len(mounts) > 0 {
+		container["volumeMounts"] = mounts
+	}
+
+	podSpec := object{"containers": []object{container}}
+	if len(volumes) > 0 {
+		podSpec["volumes"] = volumes
+	}
+	replicas := 1
+	if s.Replicas != nil {
+		replicas = *s.Replicas
+	}
+	selector := map[string]interface{}{appLabel: m.Name, "app": s.Name}
+	objects = append(objects, object{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   deploymentMetadata,
+		"spec": object{
+			"replicas": replicas,
+			"selector": object{"matchLabels": selector},
+			"template": object{
+				"metadata": object{"labels": labels},
+				"spec":     podSpec,
+			},
+		},
+	})
+
+	if s.Port > 0 {
+		objects = append(objects, object{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata":   metadata(s.Name),
+			"spec": object{
+				"selector": selector,
+				"ports":    []object{{"name": fmt.Sprintf("%d-tcp", s.Port), "port": s.Port, "targetPort": s.Port, "protocol": "TCP"}},
+			},
+		})
+	}
+
+	for i, r := range s.Routes {
+		name := s.Name
+		if i > 0 {
+			name = fmt.Sprintf("%s-%d", s.Name, i)
+		}
+		spec := object{
+			"to":   object{"kind": "Service", "name": s.Name},
+			"port": object{"targetPort": fmt.Sprintf("%d-tcp", s.Port)},
+		}
+		if len(r.Host) > 0 {
+			spec["host"] = r.Host
+		}
+		if len(r.Path) > 0 {
+			spec["path"] = r.Path
+		}
+		if r.TLS {
+			spec["tls"] = object{"termination": "edge", "insecureEdgeTerminationPolicy": "Redirect"}
+		}
+		objects = append(objects, object{
+			"apiVersion": "route.openshift.io/v1",
+			"kind":       "Route",
+			"metadata":   metadata(name),
+			"spec":       spec,
+		})
+	}
+
+	return objects, nil
+}
+
+// renderEnv returns env of a container sorted by name.
+func renderEnv(env map[string]string) []object {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result []object
+	for _, name := range names {
+		result = append(result, object{"name": name, "value": env[name]})
+	}
+	return result
+}
+
+func renderResources(resources ManifestResources) object {
+	limits := object{}
+	if len(resources.CPU) > 0 {
+		limits["cpu"] = resources.CPU
+	}
+	if len(resources.Memory) > 0 {
+		limits["memory"] = resources.Memory
+	}
+	return limits
+}
+
+// marshalObjects returns objects as a multi-document yaml.
+func marshalObjects(objects []object) ([]byte, error) {
+	var data []byte
+	for _, o := range objects {
+		document, err := yaml.Marshal(o)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, []byte("---\n")...)
+		data = append(data, document...)
+	}
+	return data, nil
+}
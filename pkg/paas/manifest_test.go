@@ -0,0 +1,171 @@
+package paas
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// findNamedObject returns the object of kind named name, failing the test if there is none.
+func findNamedObject(t *testing.T, objects []object, kind, name string) object {
+	t.Helper()
+	for _, o := range objects {
+		if o["kind"] == kind && o["metadata"].(object)["name"] == name {
+			return o
+		}
+	}
+	t.Fatalf("no %s %q in rendered objects %v", kind, name, objects)
+	return nil
+}
+
+// deploymentContainer returns the only container of a rendered Deployment.
+func deploymentContainer(t *testing.T, deployment object) object {
+	t.Helper()
+	containers := deployment["spec"].(object)["template"].(object)["spec"].(object)["containers"].([]object)
+	if len(containers) != 1 {
+		t.Fatalf("deployment has %d containers, want 1", len(containers))
+	}
+	return containers[0]
+}
+
+func TestRenderBuiltService(t *testing.T) {
+	manifest := Manifest{
+		ApiVersion: manifestApiVersion,
+		Name:       "shop",
+		Services: []ManifestService{{
+			Name:  "web",
+			Build: &ManifestBuild{Git: "https://example.com/web.git", Ref: "main", Builder: "python:3.9"},
+			Port:  8080,
+		}},
+	}
+	objects, err := manifest.render()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := []string{}
+	for _, o := range objects {
+		kinds = append(kinds, o["kind"].(string))
+		if labels := o["metadata"].(object)["labels"].(map[string]interface{}); labels[appLabel] != "shop" {
+			t.Errorf("%s is labeled %v, want %s=shop", o["kind"], labels, appLabel)
+		}
+	}
+	if expected := []string{"ImageStream", "BuildConfig", "Deployment", "Service"}; !reflect.DeepEqual(kinds, expected) {
+		t.Errorf("rendered kinds = %v, want %v", kinds, expected)
+	}
+
+	buildSpec := findNamedObject(t, objects, "BuildConfig", "web")["spec"].(object)
+	expectedSource := object{"type": "Git", "git": object{"uri": "https://example.com/web.git", "ref": "main"}}
+	if !reflect.DeepEqual(buildSpec["source"], expectedSource) {
+		t.Errorf("build source = %v, want %v", buildSpec["source"], expectedSource)
+	}
+	if expected := []object{{"type": "ConfigChange"}}; !reflect.DeepEqual(buildSpec["triggers"], expected) {
+		t.Errorf("build triggers = %v, want %v", buildSpec["triggers"], expected)
+	}
+
+	deployment := findNamedObject(t, objects, "Deployment", "web")
+	container := deploymentContainer(t, deployment)
+	if image := container["image"]; image != " " {
+		t.Errorf("image of a container set by the image trigger = %q, want placeholder \" \"", image)
+	}
+
+	annotations := deployment["metadata"].(object)["annotations"].(object)
+	var triggers []map[string]interface{}
+	if err := json.Unmarshal([]byte(annotations[imageTriggerAnnotation].(string)), &triggers); err != nil {
+		t.Fatalf("invalid image trigger annotation: %v", err)
+	}
+	expectedTriggers := []map[string]interface{}{{
+		"from":      map[string]interface{}{"kind": "ImageStreamTag", "name": "web:latest"},
+		"fieldPath": `spec.template.spec.containers[?(@.name=="web")].image`,
+	}}
+	if !reflect.DeepEqual(triggers, expectedTriggers) {
+		t.Errorf("image triggers = %v, want %v", triggers, expectedTriggers)
+	}
+}
+
+func TestRenderImageService(t *testing.T) {
+	replicas := 2
+	manifest := Manifest{
+		ApiVersion: manifestApiVersion,
+		Name:       "shop",
+		Services: []ManifestService{{
+			Name:      "cache",
+			Image:     "redis:6",
+			Replicas:  &replicas,
+			Env:       map[string]string{"B": "2", "A": "1"},
+			Resources: ManifestResources{CPU: "500m", Memory: "256Mi"},
+			Volumes:   []ManifestVolume{{Name: "data", MountPath: "/data", Size: "1Gi"}},
+			DependsOn: []string{"db", "queue"},
+		}},
+	}
+	objects, err := manifest.render()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deployment := findNamedObject(t, objects, "Deployment", "cache")
+	container := deploymentContainer(t, deployment)
+	if container["image"] != "redis:6" {
+		t.Errorf("container image = %v, want redis:6", container["image"])
+	}
+	if expected := []object{{"name": "A", "value": "1"}, {"name": "B", "value": "2"}}; !reflect.DeepEqual(container["env"], expected) {
+		t.Errorf("container env = %v, want %v", container["env"], expected)
+	}
+	limits := object{"cpu": "500m", "memory": "256Mi"}
+	if expected := (object{"limits": limits, "requests": limits}); !reflect.DeepEqual(container["resources"], expected) {
+		t.Errorf("container resources = %v, want %v", container["resources"], expected)
+	}
+	if replicas := deployment["spec"].(object)["replicas"]; replicas != 2 {
+		t.Errorf("replicas = %v, want 2", replicas)
+	}
+
+	annotations := deployment["metadata"].(object)["annotations"].(object)
+	if _, ok := annotations[imageTriggerAnnotation]; ok {
+		t.Errorf("image trigger is rendered for a service running an image")
+	}
+	if annotations[dependsOnAnnotation] != "db,queue" {
+		t.Errorf("%s annotation = %v, want db,queue", dependsOnAnnotation, annotations[dependsOnAnnotation])
+	}
+
+	claim := findNamedObject(t, objects, "PersistentVolumeClaim", "cache-data")
+	if size := claim["spec"].(object)["resources"].(object)["requests"].(object)["storage"]; size != "1Gi" {
+		t.Errorf("claim size = %v, want 1Gi", size)
+	}
+}
+
+func TestRenderRoutes(t *testing.T) {
+	manifest := Manifest{
+		ApiVersion: manifestApiVersion,
+		Name:       "shop",
+		Services: []ManifestService{{
+			Name:   "web",
+			Image:  "nginx",
+			Port:   80,
+			Routes: []ManifestRoute{{}, {Host: "shop.example.com", Path: "/api", TLS: true}},
+		}},
+	}
+	objects, err := manifest.render()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ports := findNamedObject(t, objects, "Service", "web")["spec"].(object)["ports"].([]object); ports[0]["name"] != "80-tcp" {
+		t.Errorf("service ports = %v, want port named 80-tcp", ports)
+	}
+
+	generated := findNamedObject(t, objects, "Route", "web")["spec"].(object)
+	if _, ok := generated["host"]; ok {
+		t.Errorf("host of route without host is rendered as %v", generated["host"])
+	}
+
+	expected := object{
+		"to":   object{"kind": "Service", "name": "web"},
+		"port": object{"targetPort": "80-tcp"},
+		"host": "shop.example.com",
+		"path": "/api",
+		"tls":  object{"termination": "edge", "insecureEdgeTerminationPolicy": "Redirect"},
+	}
+	if spec := findNamedObject(t, objects, "Route", "web-1")["spec"]; !reflect.DeepEqual(spec, expected) {
+		t.Errorf("route spec = %v, want %v", spec, expected)
+	}
+}
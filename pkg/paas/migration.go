@@ -6,17 +6,15 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
-	"os"
 	"strconv"
 	"strings"
 	"text/tabwriter"
 	"time"
 
-	"github.com/arvancloud/cli/pkg/api"
 	"github.com/arvancloud/cli/pkg/config"
+	"github.com/arvancloud/cli/pkg/options"
 	"github.com/arvancloud/cli/pkg/utl"
 
 	"github.com/gosuri/uilive"
@@ -25,6 +23,7 @@ import (
 	"github.com/spf13/cobra"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/client-go/rest"
 )
 
@@ -101,77 +100,64 @@ type ProgressResponse struct {
 }
 
 // NewCmdMigrate returns new cobra commad enables user to migrate namespaces to another region on arvan servers.
-func NewCmdMigrate(in io.Reader, out, errout io.Writer) *cobra.Command {
+func NewCmdMigrate(o *options.Options) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "migrate",
 		Short: "Migrate namespaces to destination region",
 		Long:  migrateLong,
 		Run: func(c *cobra.Command, args []string) {
-			explainOut := term.NewResponsiveWriter(out)
+			explainOut := term.NewResponsiveWriter(o.Out)
 			c.SetOutput(explainOut)
 
-			currentRegionName := getCurrentRegion()
+			currentRegionName := getCurrentRegion(o)
 
 			request := Request{
 				Source: currentRegionName,
 			}
 
-			response, err := httpGet(fmt.Sprintf(migrationEndpoint, request.Source))
-			if err != nil {
-				failureOutput(err.Error())
-				return
-			}
+			response, err := httpGet(o, fmt.Sprintf(migrationEndpoint, request.Source))
+			o.CheckErr(err)
 
 			if response.StatusCode == http.StatusBadRequest {
-				failureOutput(response.Message)
-
-				return
+				o.CheckErr(utl.NewError(utl.KindValidation, errors.New(response.Message)))
 			}
 
 			if response.StatusCode == http.StatusOK && (response.State == Completed || response.State == Failed) {
-				fmt.Printf("\nLast migration report of projetct \"%s\" is as bellow:\n", response.Namespace)
-				migrate(request)
-				reMigrationConfirmed := reMigrationConfirm(in, explainOut)
+				fmt.Fprintf(explainOut, "\nLast migration report of projetct \"%s\" is as bellow:\n", response.Namespace)
+				_ = migrate(o, request)
+				reMigrationConfirmed, err := reMigrationConfirm(o.In, explainOut)
+				o.CheckErr(err)
 				if !reMigrationConfirmed {
 					return
 				}
 			}
 
 			if response.State == Completed || response.State == Failed || response.StatusCode == http.StatusNotFound {
-				project, err := getSelectedProject(in, explainOut)
-				if err != nil {
-					failureOutput(err.Error())
+				project, err := getSelectedProject(o, explainOut)
+				o.CheckErr(err)
 
-					return
-				}
-
-				destinationRegion, err := getZoneByName(bamdad)
-				utl.CheckErr(err)
+				destinationRegion, err := getZoneByName(o, bamdad)
+				o.CheckErr(err)
 
 				if currentRegionName == getRegionFromEndpoint(destinationRegion.Endpoint) {
-					log.Printf("can not migrate to this region")
-					return
+					o.CheckErr(utl.Errorf(utl.KindValidation, "can not migrate to this region"))
 				}
 
-				confirmed := migrationConfirm(project, getRegionFromEndpoint(destinationRegion.Endpoint), in, explainOut)
+				confirmed, err := migrationConfirm(project, currentRegionName, getRegionFromEndpoint(destinationRegion.Endpoint), o.In, explainOut)
+				o.CheckErr(err)
 				if !confirmed {
-					return
+					o.CheckErr(utl.Errorf(utl.KindUserAborted, "migration aborted"))
 				}
 
 				request.Namespace = project
 				request.Destination = fmt.Sprintf("%s-%s", destinationRegion.RegionName, destinationRegion.Name)
 
-				err = httpPost(fmt.Sprintf(migrationEndpoint, request.Source), request)
-				if err != nil {
-					failureOutput(err.Error())
-					return
-				}
+				err = httpPost(o, fmt.Sprintf(migrationEndpoint, request.Source), request)
+				o.CheckErr(err)
 			}
 
-			err = migrate(request)
-			if err != nil {
-				failureOutput(err.Error())
-			}
+			err = migrate(o, request)
+			o.CheckErr(err)
 		},
 	}
 
@@ -179,23 +165,23 @@ func NewCmdMigrate(in io.Reader, out, errout io.Writer) *cobra.Command {
 }
 
 // reMigrationConfirm makes sure that user enters yes/no correctly.
-func reMigrationConfirm(in io.Reader, writer io.Writer) bool {
+func reMigrationConfirm(in io.Reader, writer io.Writer) (bool, error) {
 	inputExplain := "Do you want to run a new migration?[y/N]: "
 
 	defaultVal := "N"
 
-	value := utl.ReadInput(inputExplain, defaultVal, writer, in, confirmationValidate)
-	return value == "y"
+	value, err := utl.ReadInput(inputExplain, defaultVal, writer, in, confirmationValidate)
+	return value == "y", err
 }
 
 // newProjectConfirm makes sure that user enters yes/no correctly.
-func newProjectConfirm(in io.Reader, writer io.Writer) bool {
+func newProjectConfirm(in io.Reader, writer io.Writer) (bool, error) {
 	inputExplain := "Do you want to continue?[y/N]: "
 
 	defaultVal := "N"
 
-	value := utl.ReadInput(inputExplain, defaultVal, writer, in, confirmationValidate)
-	return value == "y"
+	value, err := utl.ReadInput(inputExplain, defaultVal, writer, in, confirmationValidate)
+	return value == "y", err
 }
 
 // confirmationValidate checks yes/no entry
@@ -207,15 +193,15 @@ func confirmationValidate(input string) (bool, error) {
 }
 
 // getSelectedProject gets intending namespace to migrate.
-func getSelectedProject(in io.Reader, writer io.Writer) (string, error) {
-	projects, err := projectList()
+func getSelectedProject(o *options.Options, writer io.Writer) (string, error) {
+	projects, err := projectList(o)
 
 	if err != nil {
 		return "", err
 	}
 
 	if len(projects) < 1 {
-		return "", errors.New("no project to migrate")
+		return "", utl.NewError(utl.KindNotFound, errors.New("no project to migrate"))
 	}
 
 	explain := "Select project:\n"
@@ -231,7 +217,11 @@ func getSelectedProject(in io.Reader, writer io.Writer) (string, error) {
 
 	validator := projectValidator{len(projects)}
 
-	projectIndex, err := strconv.Atoi(utl.ReadInput(inputExplain, defaultVal, writer, in, validator.validate))
+	input, err := utl.ReadInput(inputExplain, defaultVal, writer, o.In, validator.validate)
+	if err != nil {
+		return "", err
+	}
+	projectIndex, err := strconv.Atoi(input)
 	if err != nil {
 		return "", err
 	}
@@ -253,13 +243,11 @@ func (p projectValidator) validate(input string) (bool, error) {
 }
 
 // getCurrentRegion returns users current region, fetched from config, in string.
-func getCurrentRegion() string {
-	_, err := config.LoadConfigFile()
-	utl.CheckErr(err)
-
-	arvanConfig := config.GetConfigInfo()
+func getCurrentRegion(o *options.Options) string {
+	_, err := o.Config.Load()
+	o.CheckErr(err)
 
-	return getRegionFromEndpoint(arvanConfig.GetServer())
+	return getRegionFromEndpoint(o.Config.GetServer())
 }
 
 // getRegionFromEndpoint parses endpoint to return region name.
@@ -283,12 +271,12 @@ func sprintProjects(projects []string) string {
 }
 
 // migrationConfirm gets confirmation of proceeding namespace migration by asking user to enter namespace's name.
-func migrationConfirm(project, region string, in io.Reader, writer io.Writer) bool {
-	explain := fmt.Sprintf("\nYou're about to migrate \"%s\" from region \"%s\" to \"%s\".\n\n"+yellowColor+"WARNING:\nThis will STOP applications during migration process. Your data would still be safe and available in source region. Migration is running in the background and may take a while. You can optionally detach(Ctrl+C) for now and continue monitoring the process after using 'arvan paas migrate'."+resetColor+"\n\n", project, getCurrentRegion(), region)
+func migrationConfirm(project, currentRegion, region string, in io.Reader, writer io.Writer) (bool, error) {
+	explain := fmt.Sprintf("\nYou're about to migrate \"%s\" from region \"%s\" to \"%s\".\n\n"+yellowColor+"WARNING:\nThis will STOP applications during migration process. Your data would still be safe and available in source region. Migration is running in the background and may take a while. You can optionally detach(Ctrl+C) for now and continue monitoring the process after using 'arvan paas migrate'."+resetColor+"\n\n", project, currentRegion, region)
 
 	_, err := fmt.Fprint(writer, explain)
 	if err != nil {
-		return false
+		return false, err
 	}
 	inputExplain := fmt.Sprintf("Please enter project's name [%s] to proceed: ", project)
 
@@ -296,8 +284,8 @@ func migrationConfirm(project, region string, in io.Reader, writer io.Writer) bo
 
 	v := confirmationValidator{project: project}
 
-	value := utl.ReadInput(inputExplain, defaultVal, writer, in, v.confirmationValidate)
-	return value == project
+	value, err := utl.ReadInput(inputExplain, defaultVal, writer, in, v.confirmationValidate)
+	return value == project, err
 }
 
 type confirmationValidator struct {
@@ -313,9 +301,11 @@ func (v confirmationValidator) confirmationValidate(input string) (bool, error)
 }
 
 // migrate sends migration request and displays response.
-func migrate(request Request) error {
+// It returns an error if the migration or monitoring it fails.
+func migrate(o *options.Options, request Request) error {
 	// init writer to update lines
 	uiliveWriter := uilive.New()
+	uiliveWriter.Out = o.Out
 	uiliveWriter.Start()
 
 	// init writer to display lines in column
@@ -324,11 +314,14 @@ func migrate(request Request) error {
 
 	stopChannel := make(chan bool, 1)
 
-	doEvery(interval*time.Second, stopChannel, func() {
-		response, err := httpGet(fmt.Sprintf(migrationEndpoint, request.Source))
+	var migrationErr error
+
+	doEvery(o.Clock, interval*time.Second, stopChannel, func() {
+		response, err := httpGet(o, fmt.Sprintf(migrationEndpoint, request.Source))
 		if err != nil {
-			failureOutput(err.Error())
+			migrationErr = err
 			stopChannel <- true
+			uiliveWriter.Stop()
 			return
 		}
 
@@ -339,7 +332,7 @@ func migrate(request Request) error {
 			tabWriter.Flush()
 			uiliveWriter.Stop()
 
-			successOutput(response.Steps[len(response.Steps)-1].Data)
+			successOutput(o.Out, response.Steps[len(response.Steps)-1].Data)
 		}
 
 		if response.State == Failed {
@@ -347,21 +340,29 @@ func migrate(request Request) error {
 			tabWriter.Flush()
 			uiliveWriter.Stop()
 
-			failureOutput(response.Steps[len(response.Steps)-1].Data.Detail)
+			failureOutput(o.ErrOut, response.Steps[len(response.Steps)-1].Data.Detail)
+			migrationErr = utl.NewError(utl.KindServer, errors.New("migration failed"))
 		}
 	})
 
-	return nil
+	return migrationErr
 }
 
 // doEvery runs given function in periods of 'd' and stops using stopChannel.
-func doEvery(d time.Duration, stopChannel chan bool, f func()) {
-	ticker := time.NewTicker(d)
+func doEvery(c clock.Clock, d time.Duration, stopChannel chan bool, f func()) {
+	ticker := c.NewTicker(d)
 
 	for {
 		f()
+		// f may stop while a tick is already pending, which must not run it again
 		select {
-		case <-ticker.C:
+		case <-stopChannel:
+			ticker.Stop()
+			return
+		default:
+		}
+		select {
+		case <-ticker.C():
 			continue
 		case <-stopChannel:
 			ticker.Stop()
@@ -392,31 +393,31 @@ func sprintResponse(response ProgressResponse, w io.Writer) error {
 }
 
 // httpPost sends POST request to inserted url.
-func httpPost(endpoint string, payload interface{}) error {
+func httpPost(o *options.Options, endpoint string, payload interface{}) error {
 	requestBody, err := json.Marshal(payload)
 	if err != nil {
 		return err
 	}
-	arvanConfig := config.GetConfigInfo()
+	arvanConfig := o.Config
 	arvanURL, err := url.Parse(arvanConfig.GetServer())
 	if err != nil {
-		return fmt.Errorf("invalid config")
+		return utl.Errorf(utl.KindValidation, "invalid config")
 	}
 
 	httpReq, err := http.NewRequest(http.MethodPost, arvanURL.Scheme+"://"+arvanURL.Host+endpoint, bytes.NewBuffer(requestBody))
 	if err != nil {
 		return err
 	}
-	apikey := arvanConfig.GetApiKey()
-	if apikey != "" {
-		httpReq.Header.Add("Authorization", apikey)
+	authorization := getArvanAuthorization(arvanConfig)
+	if authorization != "" {
+		httpReq.Header.Add("Authorization", authorization)
 	}
 
 	httpReq.Header.Add("accept", "application/json")
 	httpReq.Header.Add("User-Agent", rest.DefaultKubernetesUserAgent())
-	httpResp, err := http.DefaultClient.Do(httpReq)
+	httpResp, err := o.Client.HTTPClient.Do(httpReq)
 	if err != nil {
-		return err
+		return utl.NewError(utl.KindNetwork, err)
 	}
 
 	// read body
@@ -434,35 +435,35 @@ func httpPost(endpoint string, payload interface{}) error {
 	}
 
 	if httpResp.StatusCode != http.StatusOK && httpResp.StatusCode != http.StatusFound {
-		return errors.New(response.Message)
+		return utl.NewError(statusErrorKind(httpResp.StatusCode), errors.New(response.Message))
 	}
 
 	return nil
 }
 
 // httpGet sends GET request to inserted url.
-func httpGet(endpoint string) (*ProgressResponse, error) {
-	arvanConfig := config.GetConfigInfo()
+func httpGet(o *options.Options, endpoint string) (*ProgressResponse, error) {
+	arvanConfig := o.Config
 	arvanURL, err := url.Parse(arvanConfig.GetServer())
 	if err != nil {
-		return nil, fmt.Errorf("invalid config")
+		return nil, utl.Errorf(utl.KindValidation, "invalid config")
 	}
 
 	httpReq, err := http.NewRequest(http.MethodGet, arvanURL.Scheme+"://"+arvanURL.Host+endpoint, bytes.NewBuffer([]byte{}))
 	if err != nil {
 		return nil, err
 	}
-	apikey := arvanConfig.GetApiKey()
-	if apikey != "" {
-		httpReq.Header.Add("Authorization", apikey)
+	authorization := getArvanAuthorization(arvanConfig)
+	if authorization != "" {
+		httpReq.Header.Add("Authorization", authorization)
 	}
 
 	httpReq.Header.Add("accept", "application/json")
 	httpReq.Header.Add("User-Agent", rest.DefaultKubernetesUserAgent())
-	httpResp, err := http.DefaultClient.Do(httpReq)
+	httpResp, err := o.Client.HTTPClient.Do(httpReq)
 	if err != nil {
-		failureOutput("Migration is running in the background. You can continue monitoring the process using 'arvan paas migrate'.")
-		return nil, err
+		failureOutput(o.ErrOut, "Migration is running in the background. You can continue monitoring the process using 'arvan paas migrate'.")
+		return nil, utl.NewError(utl.KindNetwork, err)
 	}
 
 	// read body
@@ -476,8 +477,8 @@ func httpGet(endpoint string) (*ProgressResponse, error) {
 	var response ProgressResponse
 	err = json.Unmarshal(responseBody, &response)
 	if err != nil {
-		failureOutput("Migration is running in the background. You can continue monitoring the process using 'arvan paas migrate'.")
-		return nil, err
+		failureOutput(o.ErrOut, "Migration is running in the background. You can continue monitoring the process using 'arvan paas migrate'.")
+		return nil, utl.NewError(statusErrorKind(httpResp.StatusCode), err)
 	}
 
 	response.StatusCode = httpResp.StatusCode
@@ -485,17 +486,32 @@ func httpGet(endpoint string) (*ProgressResponse, error) {
 	return &response, nil
 }
 
+// statusErrorKind returns the error kind matching an unsuccessful http status code.
+func statusErrorKind(statusCode int) utl.ErrorKind {
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return utl.KindAuth
+	case statusCode == http.StatusNotFound:
+		return utl.KindNotFound
+	case statusCode >= 400 && statusCode < 500:
+		return utl.KindValidation
+	case statusCode >= 500:
+		return utl.KindServer
+	}
+	return utl.KindUnknown
+}
+
 // failureOutput displays failure output.
-func failureOutput(message string) {
-	fmt.Println(redColor + "\nFAILED: " + message + resetColor)
+func failureOutput(w io.Writer, message string) {
+	fmt.Fprintln(w, redColor+"\nFAILED: "+message+resetColor)
 }
 
 // successOutput displays success output.
-func successOutput(data StepData) {
-	fmt.Println("\nNamespaces successfully migrated!")
+func successOutput(w io.Writer, data StepData) {
+	fmt.Fprintln(w, "\nNamespaces successfully migrated!")
 
 	if len(data.Source.Services) > 0 {
-		ipTable := tablewriter.NewWriter(os.Stdout)
+		ipTable := tablewriter.NewWriter(w)
 		ipTable.SetHeader([]string{"Old IPs", "New IPs"})
 
 		for i := 0; i < len(data.Source.Services); i++ {
@@ -521,9 +537,9 @@ func successOutput(data StepData) {
 	}
 
 	if len(freeSourceDomains) > 0 {
-		fmt.Println("Free domains changed successfully:")
+		fmt.Fprintln(w, "Free domains changed successfully:")
 
-		freeDomainTable := tablewriter.NewWriter(os.Stdout)
+		freeDomainTable := tablewriter.NewWriter(w)
 		freeDomainTable.SetHeader([]string{"old free domains", "new free domains"})
 
 		for i := 0; i < len(freeSourceDomains); i++ {
@@ -534,7 +550,7 @@ func successOutput(data StepData) {
 	}
 
 	if len(nonfreeSourceDomains) > 0 {
-		nonFreeDomainTable := tablewriter.NewWriter(os.Stdout)
+		nonFreeDomainTable := tablewriter.NewWriter(w)
 		nonFreeDomainTable.SetHeader([]string{"non-free domains"})
 
 		for i := 0; i < len(nonfreeSourceDomains); i++ {
@@ -543,29 +559,29 @@ func successOutput(data StepData) {
 
 		nonFreeDomainTable.Render()
 
-		gatewayTable := tablewriter.NewWriter(os.Stdout)
+		gatewayTable := tablewriter.NewWriter(w)
 		gatewayTable.SetHeader([]string{"old gateway", "new gateway"})
 
-		fmt.Println("For non-free domains above, please change gateway in DNS provider as bellow:")
+		fmt.Fprintln(w, "For non-free domains above, please change gateway in DNS provider as bellow:")
 		gatewayTable.Append([]string{redColor + data.Source.Gateway + resetColor, greenColor + data.Destination.Gateway + resetColor})
 		gatewayTable.Render()
 	}
 }
 
 // getZoneByName gets zone from list of active zones giving it's name.
-func getZoneByName(name string) (*config.Zone, error) {
-	regions, err := api.GetZones()
+func getZoneByName(o *options.Options, name string) (*config.Zone, error) {
+	regions, err := o.Client.GetZones()
 	if err != nil {
 		return nil, err
 	}
 	if len(regions.Zones) < 1 {
-		return nil, errors.New("invalid region info")
+		return nil, utl.NewError(utl.KindServer, errors.New("invalid region info"))
 	}
 
 	upZones, _ := getUpAndDownZones(regions.Zones)
 
 	if len(upZones) < 1 {
-		return nil, errors.New("no active region available")
+		return nil, utl.NewError(utl.KindServer, errors.New("no active region available"))
 	}
 
 	for i, zone := range upZones {
@@ -574,5 +590,5 @@ func getZoneByName(name string) (*config.Zone, error) {
 		}
 	}
 
-	return nil, errors.New("destination region not found")
+	return nil, utl.NewError(utl.KindNotFound, errors.New("destination region not found"))
 }
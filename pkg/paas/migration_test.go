@@ -0,0 +1,175 @@
+package paas_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/arvancloud/cli/pkg/api/apitest"
+	"github.com/arvancloud/cli/pkg/cli/clitest"
+	"github.com/arvancloud/cli/pkg/paas"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+// migrationSteps returns steps of a migration, the ones before done completed and the rest pending.
+func migrationSteps(done int, data paas.StepData) []paas.Step {
+	titles := []string{"Stop applications", "Copy data", "Start applications"}
+	steps := make([]paas.Step, len(titles))
+	for i, title := range titles {
+		state := string(paas.Pending)
+		if i < done {
+			state = string(paas.Completed)
+		}
+		steps[i] = paas.Step{Order: i + 1, Step: strings.ToLower(title), Title: title, State: state}
+	}
+	steps[len(steps)-1].Data = data
+	return steps
+}
+
+// useSteppingClock makes commands run by h use a fake clock stepped by a minute whenever they wait on it,
+// so polls don't wait.
+func useSteppingClock(t *testing.T, h *clitest.Harness) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	h.Clock = fakeClock
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Millisecond):
+				if fakeClock.HasWaiters() {
+					fakeClock.Step(time.Minute)
+				}
+			}
+		}
+	}()
+	t.Cleanup(func() { close(done) })
+}
+
+// newMigrationHarness returns a Harness with project "web" in zone at1, destination zone ba1 of migrations
+// and a stepping clock, so polls of the migration don't wait.
+func newMigrationHarness(t *testing.T) *clitest.Harness {
+	h := clitest.New(t)
+	h.Server.AddZone("ir-thr", "ba1", "UP")
+	h.Server.Projects["at1"] = []string{"web"}
+	useSteppingClock(t, h)
+	return h
+}
+
+func TestMigrate(t *testing.T) {
+	h := newMigrationHarness(t)
+	data := paas.StepData{
+		Source:      paas.ZoneInfo{Services: []paas.Service{{Name: "web", IP: "10.0.0.1"}}, Gateway: "old.example.com"},
+		Destination: paas.ZoneInfo{Services: []paas.Service{{Name: "web", IP: "10.0.1.1"}}, Gateway: "new.example.com"},
+	}
+	h.Server.Migration = &apitest.Migration{
+		Message: "no migration found",
+		Progress: []paas.ProgressResponse{
+			{State: paas.Running, Namespace: "web", Steps: migrationSteps(0, paas.StepData{})},
+			{State: paas.Running, Namespace: "web", Steps: migrationSteps(1, paas.StepData{})},
+			{State: paas.Running, Namespace: "web", Steps: migrationSteps(2, paas.StepData{})},
+			{State: paas.Completed, Namespace: "web", Steps: migrationSteps(3, data)},
+		},
+	}
+
+	result := h.Run("1\nweb\n", "paas", "migrate")
+	if result.ExitCode != 0 {
+		t.Fatalf("migrate failed:\n%s", result)
+	}
+
+	expectedRequest := paas.Request{Namespace: "web", Source: "ir-thr-at1", Destination: "ir-thr-ba1"}
+	if len(h.Server.Migration.Requests) != 1 || h.Server.Migration.Requests[0] != expectedRequest {
+		t.Errorf("migration requests = %+v, want %+v", h.Server.Migration.Requests, expectedRequest)
+	}
+
+	polls := 0
+	for _, request := range h.Server.Requests() {
+		if request == "GET /paas/v1/ir-thr-at1/migrate" {
+			polls++
+		}
+	}
+	// one request checking for a running migration, then one per step until the migration completes
+	if polls != 5 {
+		t.Errorf("migration progress was requested %d times, want 5", polls)
+	}
+
+	for _, expected := range []string{"Stop applications", "Copy data", "Start applications", "Namespaces successfully migrated!", "10.0.1.1", "new.example.com"} {
+		if !strings.Contains(result.Stdout, expected) {
+			t.Errorf("output does not contain %q:\n%s", expected, result)
+		}
+	}
+}
+
+func TestMigrateFailed(t *testing.T) {
+	h := newMigrationHarness(t)
+	h.Server.Migration = &apitest.Migration{
+		Progress: []paas.ProgressResponse{
+			{State: paas.Running, Namespace: "web", Steps: migrationSteps(0, paas.StepData{})},
+			{State: paas.Failed, Namespace: "web", Steps: migrationSteps(1, paas.StepData{Detail: "volume is too large"})},
+		},
+	}
+
+	result := h.Run("1\nweb\n", "paas", "migrate")
+	if result.ExitCode != utl.ServerErrorExitCode {
+		t.Fatalf("exit code = %d, want %d:\n%s", result.ExitCode, utl.ServerErrorExitCode, result)
+	}
+	if !strings.Contains(result.Stderr, "FAILED: volume is too large") {
+		t.Errorf("failure detail is not printed:\n%s", result)
+	}
+}
+
+func TestMigrateAborted(t *testing.T) {
+	h := newMigrationHarness(t)
+
+	result := h.Run("1\n", "paas", "migrate")
+	if result.ExitCode != utl.UserAbortedErrorExitCode {
+		t.Fatalf("exit code = %d, want %d:\n%s", result.ExitCode, utl.UserAbortedErrorExitCode, result)
+	}
+	if h.Server.Migration != nil && len(h.Server.Migration.Requests) > 0 {
+		t.Errorf("migration was started: %+v", h.Server.Migration.Requests)
+	}
+}
+
+func TestSuccessOutput(t *testing.T) {
+	tests := []struct {
+		golden string
+		data   paas.StepData
+	}{
+		{
+			golden: "migrate-success-output",
+			data: paas.StepData{
+				Source: paas.ZoneInfo{
+					Services: []paas.Service{{Name: "web", IP: "10.0.0.1"}, {Name: "db", IP: "10.0.0.2"}},
+					Domains: []paas.Domain{
+						{Name: "web", Host: "web-shop.apps.ir-thr-at1.arvan.run", IsFree: true},
+						{Name: "shop", Host: "shop.example.com"},
+					},
+					Gateway: "gateway.ir-thr-at1.arvan.run",
+				},
+				Destination: paas.ZoneInfo{
+					Services: []paas.Service{{Name: "web", IP: "10.1.0.1"}, {Name: "db", IP: "10.1.0.2"}},
+					Domains: []paas.Domain{
+						{Name: "web", Host: "web-shop.apps.ir-thr-ba1.arvan.run", IsFree: true},
+						{Name: "shop", Host: "shop.example.com"},
+					},
+					Gateway: "gateway.ir-thr-ba1.arvan.run",
+				},
+			},
+		},
+		{
+			golden: "migrate-success-output-empty",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.golden, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			paas.SuccessOutput(out, test.data)
+			clitest.AssertGolden(t, test.golden, out.String())
+		})
+	}
+}
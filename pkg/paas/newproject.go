@@ -0,0 +1,151 @@
+package paas
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	kterm "k8s.io/kubectl/pkg/util/term"
+
+	"github.com/arvancloud/cli/pkg/config"
+	"github.com/arvancloud/cli/pkg/options"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+const forceFlag = "force"
+
+// addNewProjectFlags adds --force to new-project command of paasCommand.
+func addNewProjectFlags(paasCommand *cobra.Command) {
+	for _, c := range paasCommand.Commands() {
+		if c.Name() == "new-project" {
+			c.Flags().Bool(forceFlag, false, "Create the project even if a project with the same name exists in another region or is being migrated")
+			return
+		}
+	}
+}
+
+// guardNewProject refuses to create project if a project with the same name exists in another zone or is being migrated,
+// unless --force is set. When other zones are inactive, it warns user who intends to migrate and asks for confirmation
+// if stdin is a terminal.
+func guardNewProject(o *options.Options, cmd *cobra.Command, args []string) error {
+	force, _ := cmd.Flags().GetBool(forceFlag)
+
+	zones, err := getZones(o)
+	if err != nil {
+		return utl.NewError(utl.KindOf(err), fmt.Errorf("failed to get zones: %v", err))
+	}
+
+	if len(args) > 0 && !force {
+		if err = checkDuplicateProject(o, zones, args[0]); err != nil {
+			return err
+		}
+	}
+
+	currentRegionAbbr := getCurrentRegion(o)
+
+	currentRegionName := currentRegionAbbr[strings.LastIndex(currentRegionAbbr, "-")+1:]
+
+	currentRegion, err := getZoneByName(o, currentRegionName)
+	if err != nil {
+		return err
+	}
+
+	_, inactiveZones := getActiveAndInactiveZones(zones)
+	if len(inactiveZones) > 0 && currentRegion.Active && !force {
+		fmt.Fprint(o.Out, yellowColor+"\nWARNING: "+resetColor+"If you have any intention to migrate projects, do not try to create a new project in destination region!\n\n")
+
+		if kterm.IsTerminal(o.In) {
+			confirmed, err := newProjectConfirm(o.In, o.Out)
+			if err != nil {
+				return err
+			}
+			if !confirmed {
+				return utl.Errorf(utl.KindUserAborted, "aborted")
+			}
+		}
+	}
+	return nil
+}
+
+// zoneProjectCheck holds what checkZoneProject found in a zone.
+type zoneProjectCheck struct {
+	region    string
+	exists    bool
+	migration *ProgressResponse
+	err       error
+}
+
+// checkDuplicateProject returns an error if project named name exists in another zone, or is being migrated from or
+// to any zone. UP zones are queried concurrently, like overview does. Zones which could not be reached are skipped
+// with a warning, unless it's the migration state of current zone.
+func checkDuplicateProject(o *options.Options, zones []config.Zone, name string) error {
+	currentRegion := getCurrentRegion(o)
+
+	upZones, downZones := getUpAndDownZones(zones)
+	checks := make([]zoneProjectCheck, len(upZones))
+	var wg sync.WaitGroup
+	for i := range upZones {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			checks[i] = checkZoneProject(o, upZones[i], name, zoneFullName(upZones[i]) == currentRegion)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, check := range checks {
+		if check.err != nil && check.region == currentRegion {
+			return utl.NewError(utl.KindOf(check.err), fmt.Errorf("failed to get migration state: %v", check.err))
+		}
+		if migrating := check.migration; migrating != nil && migrating.Namespace == name &&
+			(migrating.State == Pending || migrating.State == Running) {
+			return utl.Errorf(utl.KindValidation, "project %q is being migrated from %s to %s.\nWait for the migration to finish or use --force to create it anyway", name, migrating.Source, migrating.Destination)
+		}
+	}
+
+	for _, check := range checks {
+		if check.exists {
+			return utl.Errorf(utl.KindValidation, "project %q already exists in region %s.\nUse 'arvan paas region use %s' to work with it, or --force to create it in %s anyway", name, check.region, check.region, currentRegion)
+		}
+	}
+
+	for _, check := range checks {
+		if check.err != nil {
+			fmt.Fprintf(o.ErrOut, "WARNING: could not check projects of region %s: %v\n", check.region, check.err)
+		}
+	}
+	for _, zone := range downZones {
+		fmt.Fprintf(o.ErrOut, "WARNING: could not check projects of region %s, it's %s\n", zoneFullName(zone), zone.Status)
+	}
+	return nil
+}
+
+// checkZoneProject looks up the latest migration from zone and, unless it's the current zone, whether project named
+// name exists in it.
+func checkZoneProject(o *options.Options, zone config.Zone, name string, current bool) zoneProjectCheck {
+	check := zoneProjectCheck{region: zoneFullName(zone)}
+
+	check.migration, check.err = getZoneMigration(o, check.region)
+	if current {
+		return check
+	}
+
+	err := paasRequest(o, http.MethodGet, zonePaasServerBase(zone)+fmt.Sprintf(projectPath, url.PathEscape(name)), nil)
+	switch {
+	case err == nil:
+		check.exists = true
+	case utl.KindOf(err) == utl.KindNotFound || utl.KindOf(err) == utl.KindAuth:
+		// not found, or owned by someone else
+	case check.err == nil:
+		check.err = err
+	}
+	return check
+}
+
+// zonePaasServerBase returns base url of paas api of zone e.g https://example.com/o/.
+func zonePaasServerBase(zone config.Zone) string {
+	return "https://" + zone.Endpoint + paasUrlPostfix
+}
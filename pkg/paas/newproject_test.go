@@ -0,0 +1,122 @@
+package paas_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arvancloud/cli/pkg/api/apitest"
+	"github.com/arvancloud/cli/pkg/cli/clitest"
+	"github.com/arvancloud/cli/pkg/paas"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+func TestNewProjectDuplicate(t *testing.T) {
+	tests := []struct {
+		name      string
+		projects  map[string][]string
+		migration *paas.ProgressResponse
+		err       string
+	}{
+		{
+			name:     "exists in another region",
+			projects: map[string][]string{"sh1": {"shop"}},
+			err:      `project "shop" already exists in region ir-tbz-sh1`,
+		},
+		{
+			name:      "migrating to current region",
+			migration: &paas.ProgressResponse{State: paas.Running, Namespace: "shop", Source: "ir-tbz-sh1", Destination: "ir-thr-at1"},
+			err:       `project "shop" is being migrated from ir-tbz-sh1 to ir-thr-at1`,
+		},
+		{
+			name:      "migrating from current region",
+			migration: &paas.ProgressResponse{State: paas.Pending, Namespace: "shop", Source: "ir-thr-at1", Destination: "ir-tbz-sh1"},
+			err:       `project "shop" is being migrated from ir-thr-at1 to ir-tbz-sh1`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := clitest.New(t)
+			h.Server.AddZone("ir-tbz", "sh1", "UP")
+			h.Server.Projects["at1"] = []string{"web"}
+			for zone, projects := range test.projects {
+				h.Server.Projects[zone] = projects
+			}
+			if test.migration != nil {
+				h.Server.Migration = &apitest.Migration{Started: true, Progress: []paas.ProgressResponse{*test.migration}}
+			}
+
+			result := h.Run("", "paas", "new-project", "shop")
+			if result.ExitCode != utl.ValidationErrorExitCode {
+				t.Fatalf("exit code = %d, want %d:\n%s", result.ExitCode, utl.ValidationErrorExitCode, result)
+			}
+			if !strings.Contains(result.Stderr, test.err) {
+				t.Errorf("stderr does not contain %q:\n%s", test.err, result)
+			}
+			if projects := h.Server.Projects["at1"]; len(projects) != 1 {
+				t.Errorf("projects of at1 = %v, want shop not to be created", projects)
+			}
+		})
+	}
+}
+
+func TestNewProject(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		projects  map[string][]string
+		migration *paas.ProgressResponse
+	}{
+		{
+			name:     "no duplicate",
+			args:     []string{"shop"},
+			projects: map[string][]string{"sh1": {"api"}},
+		},
+		{
+			name:      "finished migration",
+			args:      []string{"shop"},
+			migration: &paas.ProgressResponse{State: paas.Completed, Namespace: "shop", Source: "ir-thr-at1", Destination: "ir-tbz-sh1"},
+		},
+		{
+			name:     "forced",
+			args:     []string{"shop", "--force"},
+			projects: map[string][]string{"sh1": {"shop"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := clitest.New(t)
+			h.Server.AddZone("ir-tbz", "sh1", "UP")
+			h.Server.Projects["at1"] = []string{"web"}
+			for zone, projects := range test.projects {
+				h.Server.Projects[zone] = projects
+			}
+			if test.migration != nil {
+				h.Server.Migration = &apitest.Migration{Started: true, Progress: []paas.ProgressResponse{*test.migration}}
+			}
+
+			result := h.Run("", append([]string{"paas", "new-project"}, test.args...)...)
+			if result.ExitCode != 0 {
+				t.Fatalf("new-project failed:\n%s", result)
+			}
+			if projects := h.Server.Projects["at1"]; len(projects) != 2 || projects[1] != "shop" {
+				t.Errorf("projects of at1 = %v, want shop to be created", projects)
+			}
+		})
+	}
+}
+
+func TestNewProjectUnreachableRegion(t *testing.T) {
+	h := clitest.New(t)
+	h.Server.AddZone("ir-tbz", "sh2", "DOWN")
+	h.Server.Projects["at1"] = []string{"web"}
+
+	result := h.Run("", "paas", "new-project", "shop")
+	if result.ExitCode != 0 {
+		t.Fatalf("new-project failed:\n%s", result)
+	}
+	if !strings.Contains(result.Stderr, "WARNING: could not check projects of region ir-tbz-sh2") {
+		t.Errorf("skipping the DOWN region is not reported:\n%s", result)
+	}
+}
@@ -0,0 +1,203 @@
+package paas
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arvancloud/cli/pkg/config"
+	"github.com/arvancloud/cli/pkg/options"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+const (
+	podsPath              = "api/v1/namespaces/%s/pods"
+	deploymentsPath       = "apis/apps/v1/namespaces/%s/deployments"
+	deploymentConfigsPath = "apis/apps.openshift.io/v1/namespaces/%s/deploymentconfigs"
+	routesPath            = "apis/route.openshift.io/v1/namespaces/%s/routes"
+
+	podPhaseRunning = "Running"
+)
+
+// ZoneOverview summarizes resources of projects in a zone.
+type ZoneOverview struct {
+	Zone     string            `json:"zone"`
+	Projects []ProjectOverview `json:"projects"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// ProjectOverview summarizes resources of a project.
+type ProjectOverview struct {
+	Name        string       `json:"name"`
+	RunningPods int          `json:"runningPods"`
+	Deployments int          `json:"deployments"`
+	Routes      int          `json:"routes"`
+	Quota       []QuotaUsage `json:"quota,omitempty"`
+}
+
+type itemList struct {
+	Items []json.RawMessage `json:"items"`
+}
+
+type podList struct {
+	Items []struct {
+		Status struct {
+			Phase string `json:"phase"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// NewCmdOverview returns new cobra commad summarizing resources of projects in all zones.
+func NewCmdOverview(o *options.Options) *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "overview",
+		Short: "Summarize projects of all regions",
+		Long: `
+    Summarize projects of all UP regions, showing running pods, deployments, routes and quota usage
+    of each project. Regions are queried concurrently.`,
+		Args: cobra.NoArgs,
+		Run: func(c *cobra.Command, args []string) {
+			o.CheckErr(validateOutputFormat(output))
+
+			zones, err := getZones(o)
+			o.CheckErr(err)
+
+			upZones, _ := getUpAndDownZones(zones)
+			overviews := getOverviews(o, upZones)
+
+			if output == outputFormatJSON {
+				o.CheckErr(printJSON(o.Out, overviews))
+				return
+			}
+			sprintOverviews(o.Out, overviews)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format. One of: json")
+
+	return cmd
+}
+
+// getOverviews summarizes zones concurrently, keeping their order.
+func getOverviews(o *options.Options, zones []config.Zone) []ZoneOverview {
+	overviews := make([]ZoneOverview, len(zones))
+
+	var wg sync.WaitGroup
+	for i := range zones {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			overviews[i] = getZoneOverview(o, zones[i])
+		}(i)
+	}
+	wg.Wait()
+
+	return overviews
+}
+
+// getZoneOverview summarizes projects of zone. Failures are reported in Error of the returned overview.
+func getZoneOverview(o *options.Options, zone config.Zone) ZoneOverview {
+	overview := ZoneOverview{
+		Zone:     zoneFullName(zone),
+		Projects: []ProjectOverview{},
+	}
+	serverBase := zonePaasServerBase(zone)
+
+	var list projectObjectList
+	if err := paasRequest(o, http.MethodGet, serverBase+projectListPath, &list); err != nil {
+		overview.Error = err.Error()
+		return overview
+	}
+
+	for _, item := range list.Items {
+		project, err := getProjectOverview(o, serverBase, item.Metadata.Name)
+		if err != nil {
+			overview.Error = err.Error()
+			return overview
+		}
+		overview.Projects = append(overview.Projects, *project)
+	}
+	sort.Slice(overview.Projects, func(i, j int) bool {
+		return overview.Projects[i].Name < overview.Projects[j].Name
+	})
+	return overview
+}
+
+// getProjectOverview counts resources of project in paas api of serverBase.
+func getProjectOverview(o *options.Options, serverBase, name string) (*ProjectOverview, error) {
+	namespace := url.PathEscape(name)
+	project := &ProjectOverview{Name: name}
+
+	var pods podList
+	if err := paasRequest(o, http.MethodGet, serverBase+fmt.Sprintf(podsPath, namespace), &pods); err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == podPhaseRunning {
+			project.RunningPods++
+		}
+	}
+
+	for _, path := range []string{deploymentsPath, deploymentConfigsPath} {
+		count, err := countItems(o, serverBase+fmt.Sprintf(path, namespace))
+		if err != nil {
+			return nil, err
+		}
+		project.Deployments += count
+	}
+
+	routes, err := countItems(o, serverBase+fmt.Sprintf(routesPath, namespace))
+	if err != nil {
+		return nil, err
+	}
+	project.Routes = routes
+
+	project.Quota, err = getQuotaUsage(o, serverBase, name)
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// countItems returns number of items listed by endpoint, zero if the resource is not served.
+func countItems(o *options.Options, endpoint string) (int, error) {
+	var list itemList
+	err := paasRequest(o, http.MethodGet, endpoint, &list)
+	if utl.KindOf(err) == utl.KindNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(list.Items), nil
+}
+
+// sprintOverviews displays projects of zones in columns.
+func sprintOverviews(out io.Writer, overviews []ZoneOverview) {
+	w := new(tabwriter.Writer)
+	w.Init(out, 0, 8, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "REGION\tPROJECT\tRUNNING PODS\tDEPLOYMENTS\tROUTES\tQUOTA")
+	for _, overview := range overviews {
+		if len(overview.Error) > 0 {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\terror: %s\n", overview.Zone, overview.Error)
+			continue
+		}
+		if len(overview.Projects) == 0 {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\t-\n", overview.Zone)
+			continue
+		}
+		for _, p := range overview.Projects {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%s\n", overview.Zone, p.Name, p.RunningPods, p.Deployments, p.Routes, sprintQuota(p.Quota))
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package paas_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arvancloud/cli/pkg/cli/clitest"
+	"github.com/arvancloud/cli/pkg/paas"
+)
+
+// newOverviewHarness returns a Harness with project "web" in zone at1 running two of its three pods, project "api"
+// in zone sh1 whose pods can not be listed and a DOWN zone sh2.
+func newOverviewHarness(t *testing.T) *clitest.Harness {
+	h := clitest.New(t)
+	h.Server.AddZone("ir-tbz", "sh1", "UP")
+	h.Server.AddZone("ir-tbz", "sh2", "DOWN")
+	h.Server.Projects["at1"] = []string{"web"}
+	h.Server.Projects["sh1"] = []string{"api"}
+	h.Server.Projects["sh2"] = []string{"db"}
+
+	pod := func(name, phase string) map[string]interface{} {
+		return map[string]interface{}{"metadata": map[string]interface{}{"name": name}, "status": map[string]interface{}{"phase": phase}}
+	}
+	named := func(name string) map[string]interface{} {
+		return map[string]interface{}{"metadata": map[string]interface{}{"name": name}}
+	}
+	h.Server.Objects["at1/api/v1/namespaces/web/pods"] = []map[string]interface{}{pod("web-1", "Running"), pod("web-2", "Running"), pod("worker-1", "Pending")}
+	h.Server.Objects["at1/apis/apps/v1/namespaces/web/deployments"] = []map[string]interface{}{named("web")}
+	h.Server.Objects["at1/apis/apps.openshift.io/v1/namespaces/web/deploymentconfigs"] = []map[string]interface{}{named("worker")}
+	h.Server.Objects["at1/apis/route.openshift.io/v1/namespaces/web/routes"] = []map[string]interface{}{named("web")}
+	return h
+}
+
+func TestOverview(t *testing.T) {
+	h := newOverviewHarness(t)
+
+	result := h.Run("", "paas", "overview")
+	if result.ExitCode != 0 {
+		t.Fatalf("overview failed:\n%s", result)
+	}
+
+	rows := map[string][]string{}
+	for _, line := range strings.Split(strings.TrimSpace(result.Stdout), "\n")[1:] {
+		fields := strings.Fields(line)
+		rows[fields[0]] = fields[1:]
+	}
+	if expected := []string{"web", "2", "2", "1", "-"}; !reflect.DeepEqual(rows["ir-thr-at1"], expected) {
+		t.Errorf("row of ir-thr-at1 = %v, want %v", rows["ir-thr-at1"], expected)
+	}
+	if row := rows["ir-tbz-sh1"]; len(row) < 5 || row[4] != "error:" {
+		t.Errorf("row of ir-tbz-sh1 = %v, want an error", row)
+	}
+	if _, ok := rows["ir-tbz-sh2"]; ok {
+		t.Errorf("DOWN region ir-tbz-sh2 is summarized:\n%s", result)
+	}
+}
+
+func TestOverviewJSON(t *testing.T) {
+	h := newOverviewHarness(t)
+
+	result := h.Run("", "paas", "overview", "-o", "json")
+	if result.ExitCode != 0 {
+		t.Fatalf("overview failed:\n%s", result)
+	}
+
+	var overviews []paas.ZoneOverview
+	if err := json.Unmarshal([]byte(result.Stdout), &overviews); err != nil {
+		t.Fatalf("output is not json: %v\n%s", err, result)
+	}
+	if len(overviews) != 2 {
+		t.Fatalf("overviews = %+v, want overviews of the UP regions", overviews)
+	}
+
+	expected := paas.ZoneOverview{
+		Zone:     "ir-thr-at1",
+		Projects: []paas.ProjectOverview{{Name: "web", RunningPods: 2, Deployments: 2, Routes: 1}},
+	}
+	if !reflect.DeepEqual(overviews[0], expected) {
+		t.Errorf("overview of ir-thr-at1 = %+v, want %+v", overviews[0], expected)
+	}
+	if overviews[1].Zone != "ir-tbz-sh1" || len(overviews[1].Error) == 0 {
+		t.Errorf("overview of ir-tbz-sh1 = %+v, want an error listing pods of api", overviews[1])
+	}
+}
@@ -1,23 +1,23 @@
 package paas
 
 import (
-	"crypto/tls"
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 	"text/tabwriter"
 
-	"github.com/arvancloud/cli/pkg/api"
 	"github.com/openshift/oc/pkg/version"
 	"gopkg.in/yaml.v2"
 	"k8s.io/client-go/rest"
 
 	"github.com/arvancloud/cli/pkg/oc"
+	"github.com/arvancloud/cli/pkg/options"
 	"github.com/spf13/cobra"
 
 	"github.com/arvancloud/cli/pkg/config"
@@ -32,162 +32,156 @@ const (
 )
 
 // NewCmdPaas return new cobra cli for paas
-func NewCmdPaas() *cobra.Command {
+func NewCmdPaas(o *options.Options) *cobra.Command {
 
-	paasCommand := oc.InitiatedCommand()
+	paasCommand := oc.InitiatedCommand(o.In, o.Out, o.ErrOut)
 
-	in, out, errout := os.Stdin, os.Stdout, os.Stderr
+	paasCommand.AddCommand(NewCmdSwitchRegion(o))
 
-	paasCommand.AddCommand(NewCmdSwitchRegion(in, out, errout))
+	paasCommand.AddCommand(NewCmdCredential(o))
 
-	migrateCommand := NewCmdMigrate(in, out, errout)
+	addProjectsCommands(paasCommand, o)
+
+	addNewProjectFlags(paasCommand)
+
+	addNewAppFlags(paasCommand)
+
+	addSecretsCommands(paasCommand, o)
+
+	paasCommand.AddCommand(NewCmdOverview(o))
+
+	paasCommand.AddCommand(NewCmdDeploy(o))
+
+	paasCommand.AddCommand(NewCmdUp(o))
+
+	paasCommand.AddCommand(NewCmdImport(o))
+
+	paasCommand.AddCommand(NewCmdExport(o))
+
+	paasCommand.AddCommand(NewCmdRestore(o))
+
+	paasCommand.AddCommand(NewCmdClone(o))
+
+	paasCommand.AddCommand(NewCmdDiffProjects(o))
+
+	paasCommand.AddCommand(NewCmdEnv(o))
+
+	paasCommand.AddCommand(NewCmdDomain(o))
+
+	migrateCommand := NewCmdMigrate(o)
 	paasCommand.AddCommand(migrateCommand)
 
 	paasCommand.PersistentPreRun = func(cmd *cobra.Command, args []string) {
-		err := prepareCommand(cmd)
-		utl.CheckErr(err)
+		err := prepareCommand(o, cmd)
+		o.CheckErr(err)
 
 		if cmd != nil {
-			err = prepareConfig(cmd)
-			utl.CheckErr(err)
+			err = prepareConfig(o, cmd)
+			o.CheckErr(err)
+		}
+
+		// To prevent duplicating projects in other regions or ones being migrated
+		if cmd.Name() == "new-project" {
+			o.CheckErr(guardNewProject(o, cmd, args))
 		}
 
-		update, err := api.CheckUpdate()
+		update, err := o.Client.CheckUpdate()
 		if err != nil {
 			return
 		}
 		if update != nil {
 			w := new(tabwriter.Writer)
-			w.Init(os.Stdout, 4, 4, 0, '\t', 0)
+			w.Init(o.Out, 4, 4, 0, '\t', 0)
 			defer w.Flush()
 			currentVersionInfo, _, err := version.ExtractVersion()
-			utl.CheckErr(err)
+			o.CheckErr(err)
 			fmt.Fprint(w, strings.Repeat("*", 50))
 			fmt.Fprint(w, fmt.Sprintf("\n\tUpdate available %s -> %s\t", currentVersionInfo.GitVersion, update.Version))
 			fmt.Fprint(w, "\n\tRun 'arvan update' to update\n")
 			fmt.Fprint(w, strings.Repeat("*", 50))
 			fmt.Fprint(w, "\n")
 		}
-
-		// To warn user not to duplicate projects if they have a migration plan
-		if cmd.Name() == "new-project" {
-			regions, err := api.GetZones()
-			if err != nil {
-				failureOutput("failed to get zones")
-				utl.CheckErr(errors.New("failed to get zones"))
-			}
-
-			if len(regions.Zones) < 1 {
-				failureOutput("invalid region info")
-				utl.CheckErr(errors.New("invalid region info"))
-			}
-
-			currentRegionAbbr := getCurrentRegion()
-
-			currentRegionName := currentRegionAbbr[strings.LastIndex(currentRegionAbbr, "-")+1:]
-
-			currentRegion, err := getZoneByName(currentRegionName)
-			utl.CheckErr(err)
-
-			_, inactiveZones := getActiveAndInactiveZones(regions.Zones)
-			if len(inactiveZones) > 0 && currentRegion.Active {
-				fmt.Print(yellowColor + "\nWARNING: " + resetColor + "If you have any intention to migrate projects, do not try to create a new project in destination region!\n\n")
-
-				if !newProjectConfirm(in, out) {
-					utl.CheckErr(errors.New(""))
-				}
-			}
-		}
 	}
 
 	return paasCommand
 }
 
-func setArvanBuilder(cmd *cobra.Command) error {
-	if cmd.Name() == "new-app" {
-		if strings.HasPrefix(cmd.Flags().Args()[0], "https") || strings.HasPrefix(cmd.Flags().Args()[0], "http") {
-			cmd.Flags().Args()[0] = "arvanbuilder:ArvanBuilder~" + cmd.Flags().Args()[0]
-		}
-	}
-	return nil
-}
-
-func prepareConfig(cmd *cobra.Command) error {
+func prepareConfig(o *options.Options, cmd *cobra.Command) error {
 	// #TODO do not use InsecureSkipVerify
-	http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	username, httpStatusCode, err := whoAmI()
+	o.Client.InsecureSkipTLSVerify()
+	username, httpStatusCode, err := whoAmI(o)
 	if err != nil {
-		if httpStatusCode == 401 {
-			return fmt.Errorf("%v\n%s", err, `Try "arvan login".`)
-		}
-		if httpStatusCode >= 500 {
-			return fmt.Errorf("%v\n%s", err, `Please try again later`)
-		}
-		return err
+		return whoAmIError(httpStatusCode, err)
 	}
 
-	projects, err := projectList()
+	projects, err := projectList(o)
 	if err != nil {
 		return err
 	}
-	if len(projects) == 0 && cmd.Name() != "new-project" {
-		return errors.New("no project found. \n To get started create new project using \"arvan paas new-project NAME\".")
+	if len(projects) == 0 && cmd.Name() != "new-project" && cmd.Name() != "overview" {
+		return utl.NewError(utl.KindNotFound, errors.New("no project found. \n To get started create new project using \"arvan paas new-project NAME\"."))
 	}
 
-	kubeConfigPath := paasConfigPath()
-	err = syncKubeConfig(kubeConfigPath, username, projects)
+	kubeConfigPath := paasConfigPath(o.Config)
+	err = syncKubeConfig(o.Config, kubeConfigPath, username, projects)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func prepareConfigSwtichRegion(cmd *cobra.Command) error {
+func prepareConfigSwtichRegion(o *options.Options, cmd *cobra.Command) error {
 	// #TODO do not use InsecureSkipVerify
-	http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	username, httpStatusCode, err := whoAmI()
+	o.Client.InsecureSkipTLSVerify()
+	username, httpStatusCode, err := whoAmI(o)
 	if err != nil {
-		if httpStatusCode == 401 {
-			return fmt.Errorf("%v\n%s", err, `Try "arvan login".`)
-		}
-		if httpStatusCode >= 500 {
-			return fmt.Errorf("%v\n%s", err, `Please try again later`)
-		}
-		return err
+		return whoAmIError(httpStatusCode, err)
 	}
 
-	projects, err := projectList()
+	projects, err := projectList(o)
 	if err != nil {
 		return err
 	}
 
-	kubeConfigPath := paasConfigPath()
-	err = syncKubeConfig(kubeConfigPath, username, projects)
+	kubeConfigPath := paasConfigPath(o.Config)
+	err = syncKubeConfig(o.Config, kubeConfigPath, username, projects)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func prepareCommand(cmd *cobra.Command) error {
-	_ = UpgradeConfigFile()
+// whoAmIError annotates an error returned by whoAmI with a hint matching httpStatusCode.
+func whoAmIError(httpStatusCode int, err error) error {
+	if httpStatusCode == 401 {
+		return utl.Errorf(utl.KindAuth, "%v\n%s", err, `Try "arvan login".`)
+	}
+	if httpStatusCode >= 500 {
+		return utl.Errorf(utl.KindServer, "%v\n%s", err, `Please try again later`)
+	}
+	return err
+}
+
+func prepareCommand(o *options.Options, cmd *cobra.Command) error {
+	_ = UpgradeConfigFile(o.Config)
 
-	arvanConfig := config.GetConfigInfo()
-	kubeConfigPath := paasConfigPath()
+	arvanConfig := o.Config
+	kubeConfigPath := paasConfigPath(arvanConfig)
 
 	err := setConfigFlag(cmd, kubeConfigPath)
 	if err != nil {
 		return err
 	}
-	if len(arvanConfig.GetApiKey()) == 0 {
-		return errors.New("no authorization credentials provided. \nTry \"arvan login\"")
+	if err := requireCredentials(o); err != nil {
+		return err
 	}
 
-	return setArvanBuilder(cmd)
+	return setArvanBuilder(o, cmd)
 }
 
-func UpgradeConfigFile() error {
-	path := paasConfigPath()
+// UpgradeConfigFile rewrites deprecated server addresses in paas kubeconfig of arvanConfig.
+func UpgradeConfigFile(arvanConfig *config.ConfigInfo) error {
+	path := paasConfigPath(arvanConfig)
 	data, err := ioutil.ReadFile(path)
 	if err != nil {
 		return err
@@ -208,8 +202,7 @@ func UpgradeConfigFile() error {
 	return nil
 }
 
-func paasConfigPath() string {
-	arvanConfig := config.GetConfigInfo()
+func paasConfigPath(arvanConfig *config.ConfigInfo) string {
 	homeDir := arvanConfig.GetHomeDir()
 	if strings.HasSuffix(homeDir, "/") {
 		return homeDir + kubeConfigFileName
@@ -226,21 +219,21 @@ func setConfigFlag(cmd *cobra.Command, kubeConfigPath string) error {
 }
 
 // #TODO Implement whoAmI
-func whoAmI() (string, int, error) {
-	httpReq, err := http.NewRequest("GET", getArvanPaasServerBase()+whoAmIPath, nil)
+func whoAmI(o *options.Options) (string, int, error) {
+	httpReq, err := http.NewRequest("GET", getArvanPaasServerBase(o.Config)+whoAmIPath, nil)
 	if err != nil {
 		return "", 0, err
 	}
 	httpReq.Header.Add("accept", "application/json")
-	httpReq.Header.Add("authorization", getArvanAuthorization())
+	httpReq.Header.Add("authorization", getArvanAuthorization(o.Config))
 	httpReq.Header.Add("User-Agent", rest.DefaultKubernetesUserAgent())
-	httpResp, err := http.DefaultClient.Do(httpReq)
+	httpResp, err := o.Client.HTTPClient.Do(httpReq)
 	if err != nil {
-		return "", 0, err
+		return "", 0, utl.NewError(utl.KindNetwork, err)
 	}
 
 	if httpResp.StatusCode != 200 {
-		return "", httpResp.StatusCode, fmt.Errorf(httpResp.Status)
+		return "", httpResp.StatusCode, errors.New(httpResp.Status)
 	}
 
 	// read body
@@ -281,27 +274,30 @@ func whoAmI() (string, int, error) {
 		}
 	}
 
-	return "", httpResp.StatusCode, errors.New("invalid authentication credentials")
+	return "", httpResp.StatusCode, utl.NewError(utl.KindAuth, errors.New("invalid authentication credentials"))
 }
 
-func projectList() ([]string, error) {
-	httpReq, err := http.NewRequest("GET", getArvanPaasServerBase()+projectListPath, nil)
+func projectList(o *options.Options) ([]string, error) {
+	httpReq, err := http.NewRequest("GET", getArvanPaasServerBase(o.Config)+projectListPath, nil)
 	if err != nil {
 		return nil, err
 	}
 	httpReq.Header.Add("accept", "application/json")
-	httpReq.Header.Add("authorization", getArvanAuthorization())
+	httpReq.Header.Add("authorization", getArvanAuthorization(o.Config))
 	httpReq.Header.Add("User-Agent", rest.DefaultKubernetesUserAgent())
-	httpResp, err := http.DefaultClient.Do(httpReq)
+	httpResp, err := o.Client.HTTPClient.Do(httpReq)
 	if err != nil {
-		return nil, err
+		return nil, utl.NewError(utl.KindNetwork, err)
 	}
 
 	// read body
 	defer httpResp.Body.Close()
 	body, err := ioutil.ReadAll(httpResp.Body)
 	if err != nil {
-		return nil, err
+		return nil, utl.NewError(utl.KindNetwork, err)
+	}
+	if httpResp.StatusCode == http.StatusUnauthorized {
+		return nil, utl.Errorf(utl.KindAuth, "%s\n%s", httpResp.Status, `Try "arvan login".`)
 	}
 	// parse response
 	var objmap map[string]*json.RawMessage
@@ -341,30 +337,86 @@ func projectList() ([]string, error) {
 			return nil, nil
 		}
 	}
-	return nil, errors.New("invalid projects response")
+	return nil, utl.NewError(utl.KindServer, errors.New("invalid projects response"))
 }
 
-func getArvanAuthorization() string {
-	arvanConfig := config.GetConfigInfo()
-	//#TODO fix this!
-	//return apiKeyHeaderKey + " " + arvanConfig.GetApiKey()
-	return arvanConfig.GetApiKey()
+// paasRequest sends a request to endpoint of paas api and parses its json response into result if it's not nil.
+func paasRequest(o *options.Options, method, endpoint string, result interface{}) error {
+	return paasRequestWithBody(o, method, endpoint, "", nil, result)
 }
 
-func getArvanPaasServerBase() string {
-	arvanConfig := config.GetConfigInfo()
+// paasRequestWithBody is paasRequest sending body of contentType.
+func paasRequestWithBody(o *options.Options, method, endpoint, contentType string, body []byte, result interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequest(method, endpoint, reqBody)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Add("accept", "application/json")
+	if len(contentType) > 0 {
+		httpReq.Header.Add("content-type", contentType)
+	}
+	httpReq.Header.Add("authorization", getArvanAuthorization(o.Config))
+	httpReq.Header.Add("User-Agent", rest.DefaultKubernetesUserAgent())
+	httpResp, err := o.Client.HTTPClient.Do(httpReq)
+	if err != nil {
+		return utl.NewError(utl.KindNetwork, err)
+	}
+
+	// read body
+	defer httpResp.Body.Close()
+	respBody, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return utl.NewError(utl.KindNetwork, err)
+	}
+	if httpResp.StatusCode == http.StatusUnauthorized {
+		return utl.Errorf(utl.KindAuth, "%s\n%s", httpResp.Status, `Try "arvan login".`)
+	}
+	if httpResp.StatusCode >= 400 {
+		var status struct {
+			Message string `json:"message"`
+		}
+		if err = json.Unmarshal(respBody, &status); err != nil || len(status.Message) == 0 {
+			status.Message = httpResp.Status
+		}
+		return utl.NewError(statusErrorKind(httpResp.StatusCode), errors.New(status.Message))
+	}
+
+	if result == nil {
+		return nil
+	}
+	// parse response
+	if err = json.Unmarshal(respBody, result); err != nil {
+		return utl.NewError(utl.KindServer, err)
+	}
+	return nil
+}
+
+// getArvanAuthorization returns Authorization header value of requests sent to arvan api server.
+func getArvanAuthorization(arvanConfig *config.ConfigInfo) string {
+	return arvanConfig.GetAuthorization()
+}
+
+func getArvanPaasServerBase(arvanConfig *config.ConfigInfo) string {
 	arvanServer := arvanConfig.GetServer()
 	return arvanServer + paasUrlPostfix
 }
 
-func syncKubeConfig(path, username string, projects []string) error {
-	arvanConfig := config.GetConfigInfo()
-	arvanHostnamePort, err := getArvanServerDomainPort()
+func syncKubeConfig(arvanConfig *config.ConfigInfo, path, username string, projects []string) error {
+	arvanHostnamePort, err := getArvanServerDomainPort(arvanConfig)
+	if err != nil {
+		return err
+	}
+
+	userInfo, err := kubeConfigUserInfo(arvanConfig)
 	if err != nil {
 		return err
 	}
 
-	kubeConfig := populateKubeConfig(getArvanPaasServerBase(), arvanHostnamePort, username, arvanConfig.GetApiKey(), projects, path)
+	kubeConfig := populateKubeConfig(getArvanPaasServerBase(arvanConfig), arvanHostnamePort, username, userInfo, projects, path)
 
 	err = writeKubeConfig(kubeConfig, path)
 	if err != nil {
@@ -373,8 +425,7 @@ func syncKubeConfig(path, username string, projects []string) error {
 	return nil
 }
 
-func getArvanServerDomainPort() (string, error) {
-	arvanConfig := config.GetConfigInfo()
+func getArvanServerDomainPort(arvanConfig *config.ConfigInfo) (string, error) {
 	arvanServer := arvanConfig.GetServer()
 	u, err := url.Parse(arvanServer)
 	if err != nil {
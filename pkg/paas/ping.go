@@ -0,0 +1,193 @@
+package paas
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arvancloud/cli/pkg/config"
+	"github.com/arvancloud/cli/pkg/options"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+const (
+	regionAuto         = "auto"
+	defaultPingTimeout = 5 * time.Second
+)
+
+// ZoneLatency holds latencies measured to endpoint of a zone.
+type ZoneLatency struct {
+	Zone   string        `json:"zone"`
+	Status string        `json:"status"`
+	TCP    time.Duration `json:"tcp"`
+	TLS    time.Duration `json:"tls"`
+	API    time.Duration `json:"api"`
+	Error  string        `json:"error,omitempty"`
+}
+
+// newCmdRegionPing returns new cobra commad measuring latency to each zone.
+func newCmdRegionPing(o *options.Options) *cobra.Command {
+	var output string
+	var timeout time.Duration
+	cmd := &cobra.Command{
+		Use:   "ping",
+		Short: "Measure latency to regions",
+		Long: `
+    Measure TCP and TLS handshake and API latency to endpoint of each UP region concurrently,
+    and list regions from the fastest to the slowest.`,
+		Args: cobra.NoArgs,
+		Run: func(c *cobra.Command, args []string) {
+			o.CheckErr(validateOutputFormat(output))
+
+			zones, err := getZones(o)
+			o.CheckErr(err)
+
+			latencies := pingZones(o, zones, timeout)
+
+			if output == outputFormatJSON {
+				o.CheckErr(printJSON(o.Out, latencies))
+				return
+			}
+			sprintLatencies(o.Out, latencies)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format. One of: json")
+	cmd.Flags().DurationVar(&timeout, "timeout", defaultPingTimeout, "Maximum time to wait for each region")
+
+	return cmd
+}
+
+// pingZones measures latency to UP zones concurrently and returns them sorted from the fastest.
+// Zones which are not UP are skipped, and ones which failed to respond are sorted last.
+func pingZones(o *options.Options, zones []config.Zone, timeout time.Duration) []ZoneLatency {
+	zones, _ = getUpAndDownZones(zones)
+	latencies := make([]ZoneLatency, len(zones))
+
+	var wg sync.WaitGroup
+	for i := range zones {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			latencies[i] = pingZone(o, zones[i], timeout)
+		}(i)
+	}
+	wg.Wait()
+
+	sort.SliceStable(latencies, func(i, j int) bool {
+		iReachable, jReachable := latencies[i].reachable(), latencies[j].reachable()
+		if iReachable != jReachable {
+			return iReachable
+		}
+		return latencies[i].total() < latencies[j].total()
+	})
+	return latencies
+}
+
+// pingZone measures latency to endpoint of zone.
+func pingZone(o *options.Options, zone config.Zone, timeout time.Duration) ZoneLatency {
+	latency := ZoneLatency{
+		Zone:   zoneFullName(zone),
+		Status: zone.Status,
+	}
+
+	endpoint, err := url.Parse("https://" + zone.Endpoint)
+	if err != nil {
+		latency.Error = err.Error()
+		return latency
+	}
+	address := endpoint.Host
+	if len(endpoint.Port()) == 0 {
+		address = net.JoinHostPort(endpoint.Hostname(), "443")
+	}
+
+	start := o.Clock.Now()
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		latency.Error = err.Error()
+		return latency
+	}
+	defer conn.Close()
+	latency.TCP = o.Clock.Since(start)
+
+	// #TODO do not use InsecureSkipVerify
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: endpoint.Hostname(), InsecureSkipVerify: true})
+	_ = tlsConn.SetDeadline(time.Now().Add(timeout))
+	start = o.Clock.Now()
+	if err = tlsConn.Handshake(); err != nil {
+		latency.Error = err.Error()
+		return latency
+	}
+	latency.TLS = o.Clock.Since(start)
+
+	httpReq, err := http.NewRequest(http.MethodGet, endpoint.String()+paasUrlPostfix+"healthz", nil)
+	if err != nil {
+		latency.Error = err.Error()
+		return latency
+	}
+	httpClient := *o.Client.HTTPClient
+	httpClient.Timeout = timeout
+	start = o.Clock.Now()
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		latency.Error = err.Error()
+		return latency
+	}
+	_, _ = io.Copy(ioutil.Discard, httpResp.Body)
+	httpResp.Body.Close()
+	latency.API = o.Clock.Since(start)
+
+	return latency
+}
+
+func (l ZoneLatency) reachable() bool {
+	return len(l.Error) == 0
+}
+
+func (l ZoneLatency) total() time.Duration {
+	return l.TCP + l.TLS + l.API
+}
+
+// getNearestZone returns the UP zone with the lowest latency.
+func getNearestZone(o *options.Options, zones []config.Zone) (*config.Zone, error) {
+	upZones, _ := getUpAndDownZones(zones)
+	if len(upZones) < 1 {
+		return nil, utl.NewError(utl.KindServer, errors.New("no active region available"))
+	}
+
+	latencies := pingZones(o, upZones, defaultPingTimeout)
+	if !latencies[0].reachable() {
+		return nil, utl.Errorf(utl.KindNetwork, "no region is reachable: %s", latencies[0].Error)
+	}
+	return findZone(upZones, latencies[0].Zone)
+}
+
+// sprintLatencies displays latencies in columns.
+func sprintLatencies(out io.Writer, latencies []ZoneLatency) {
+	w := new(tabwriter.Writer)
+	w.Init(out, 0, 8, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "NAME\tSTATUS\tTCP\tTLS\tAPI\tERROR")
+	for _, l := range latencies {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", l.Zone, l.Status, sprintLatency(l.TCP), sprintLatency(l.TLS), sprintLatency(l.API), l.Error)
+	}
+}
+
+func sprintLatency(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return d.Round(time.Millisecond).String()
+}
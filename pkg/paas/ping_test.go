@@ -0,0 +1,90 @@
+package paas
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arvancloud/cli/pkg/config"
+	"github.com/arvancloud/cli/pkg/options"
+)
+
+// newPingZone returns a zone served by a TLS server responding to api requests after delay.
+func newPingZone(t *testing.T, name string, delay time.Duration) config.Zone {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+	}))
+	t.Cleanup(server.Close)
+	return config.Zone{
+		RegionName: "ir-thr",
+		Name:       name,
+		Status:     zoneStatusUp,
+		Endpoint:   strings.TrimPrefix(server.URL, "https://") + "/paas/v1/regions/ir-thr-" + name,
+	}
+}
+
+func TestPingZones(t *testing.T) {
+	o := options.NewOptions(config.NewConfigInfo(t.TempDir()), strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{})
+	o.Client.InsecureSkipTLSVerify()
+
+	// a closed listener refuses connections
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+
+	// DOWN zones must not be probed at all
+	down, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer down.Close()
+	var downConnections int32
+	go func() {
+		for {
+			conn, err := down.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&downConnections, 1)
+			conn.Close()
+		}
+	}()
+
+	zones := []config.Zone{
+		newPingZone(t, "slow", 200*time.Millisecond),
+		{RegionName: "ir-thr", Name: "closed", Status: zoneStatusUp, Endpoint: closed.Addr().String() + "/paas/v1/regions/ir-thr-closed"},
+		{RegionName: "ir-thr", Name: "down", Status: "DOWN", Endpoint: down.Addr().String() + "/paas/v1/regions/ir-thr-down"},
+		newPingZone(t, "fast", 0),
+		newPingZone(t, "medium", 100*time.Millisecond),
+	}
+
+	latencies := pingZones(o, zones, time.Second)
+
+	var names []string
+	for _, latency := range latencies {
+		names = append(names, latency.Zone)
+	}
+	expected := []string{"ir-thr-fast", "ir-thr-medium", "ir-thr-slow", "ir-thr-closed"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Fatalf("zones = %v, want %v", names, expected)
+	}
+
+	for _, latency := range latencies[:3] {
+		if !latency.reachable() || latency.TCP == 0 || latency.TLS == 0 || latency.API == 0 {
+			t.Errorf("latency of %s = %+v, want every latency to be measured", latency.Zone, latency)
+		}
+	}
+	if latencies[3].reachable() || len(latencies[3].Error) == 0 {
+		t.Errorf("latency of %s = %+v, want a connection error", latencies[3].Zone, latencies[3])
+	}
+	if n := atomic.LoadInt32(&downConnections); n > 0 {
+		t.Errorf("DOWN zone was probed using %d connections", n)
+	}
+}
@@ -0,0 +1,211 @@
+package paas
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arvancloud/cli/pkg/options"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+const (
+	zoneBFlag   = "zone-b"
+	maskedValue = "(changed)"
+)
+
+var diffProjectsExample = `
+  # Diff resources of two projects of current region
+  arvan paas diff-projects staging production
+
+  # Diff a project before and after migration to another region
+  arvan paas diff-projects shop shop --zone-b ir-thr-at1`
+
+// fieldDiff is a field of an object with different values in two projects. Empty values are missing fields.
+type fieldDiff struct {
+	path   string
+	valueA string
+	valueB string
+}
+
+// NewCmdDiffProjects returns new cobra commad diffing resources of two projects. Like diff, it exits
+// with DefaultErrorExitCode if the projects differ.
+func NewCmdDiffProjects(o *options.Options) *cobra.Command {
+	var zoneB string
+	cmd := &cobra.Command{
+		Use:     "diff-projects PROJECT-A PROJECT-B",
+		Short:   "Diff resources of two projects",
+		Example: diffProjectsExample,
+		Args:    cobra.ExactArgs(2),
+		Run: func(c *cobra.Command, args []string) {
+			different, err := diffProjects(o, args[0], args[1], zoneB)
+			o.CheckErr(err)
+			if different {
+				o.Exit(utl.DefaultErrorExitCode)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&zoneB, zoneBFlag, "", "Region of the second project, the current region by default")
+
+	return cmd
+}
+
+// diffProjects prints differences of resources of project a of current zone and project b of zone named zoneB,
+// or current zone if it's empty. It returns whether there is any difference.
+func diffProjects(o *options.Options, a, b, zoneB string) (bool, error) {
+	baseA := getArvanPaasServerBase(o.Config)
+	baseB := baseA
+	if len(zoneB) > 0 {
+		zone, err := getZoneByRegionName(o, zoneB)
+		if err != nil {
+			return false, err
+		}
+		baseB = zonePaasServerBase(*zone)
+	}
+
+	objectsA, err := getProjectObjects(o, baseA, a, exportOptions{includeSecrets: true})
+	if err != nil {
+		return false, err
+	}
+	objectsB, err := getProjectObjects(o, baseB, b, exportOptions{includeSecrets: true})
+	if err != nil {
+		return false, err
+	}
+
+	different := false
+	for i, k := range bundleKinds {
+		// references of b to its own project are not differences
+		for _, obj := range objectsB[i] {
+			rewriteReferences(obj, b, a)
+		}
+		if sprintKindDiff(o.Out, k, a, b, objectsA[i], objectsB[i]) {
+			different = true
+		}
+	}
+	if !different {
+		fmt.Fprintf(o.Out, "No differences between projects %q and %q.\n", a, b)
+	}
+	return different, nil
+}
+
+// sprintKindDiff prints objects of kind k only in project a, only in project b, and fields of objects with
+// different values. Values of secrets are masked. It returns whether there is any difference.
+func sprintKindDiff(out io.Writer, k bundleKind, a, b string, objectsA, objectsB []object) bool {
+	byName := func(objects []object) map[string]object {
+		result := map[string]object{}
+		for _, obj := range objects {
+			result[objectName(obj)] = obj
+		}
+		return result
+	}
+	namedA, namedB := byName(objectsA), byName(objectsB)
+
+	var names []string
+	for name := range namedA {
+		names = append(names, name)
+	}
+	for name := range namedB {
+		if _, ok := namedA[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	printed := false
+	printKind := func() {
+		if !printed {
+			fmt.Fprintln(out, k.resource)
+			printed = true
+		}
+	}
+	for _, name := range names {
+		objA, inA := namedA[name]
+		objB, inB := namedB[name]
+		switch {
+		case !inB:
+			printKind()
+			fmt.Fprintf(out, "  - %s (only in %s)\n", name, a)
+		case !inA:
+			printKind()
+			fmt.Fprintf(out, "  + %s (only in %s)\n", name, b)
+		default:
+			diffs := diffObjects(objA, objB, k.kind == "Secret")
+			if len(diffs) == 0 {
+				continue
+			}
+			printKind()
+			fmt.Fprintf(out, "  ~ %s\n", name)
+			for _, d := range diffs {
+				fmt.Fprintf(out, "      %s: %s -> %s\n", d.path, sprintValue(d.valueA), sprintValue(d.valueB))
+			}
+		}
+	}
+	return printed
+}
+
+// diffObjects returns fields of a and b with different values, sorted by path. If masked, values of data
+// fields are replaced by maskedValue.
+func diffObjects(a, b object, masked bool) []fieldDiff {
+	fieldsA, fieldsB := map[string]string{}, map[string]string{}
+	flattenFields("", map[string]interface{}(a), fieldsA)
+	flattenFields("", map[string]interface{}(b), fieldsB)
+
+	paths := map[string]bool{}
+	for path := range fieldsA {
+		paths[path] = true
+	}
+	for path := range fieldsB {
+		paths[path] = true
+	}
+
+	var diffs []fieldDiff
+	for path := range paths {
+		valueA, valueB := fieldsA[path], fieldsB[path]
+		if valueA == valueB {
+			continue
+		}
+		if masked && (strings.HasPrefix(path, "data.") || strings.HasPrefix(path, "stringData.")) {
+			if len(valueA) > 0 {
+				valueA = maskedValue
+			}
+			if len(valueB) > 0 {
+				valueB = maskedValue
+			}
+		}
+		diffs = append(diffs, fieldDiff{path: path, valueA: valueA, valueB: valueB})
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].path < diffs[j].path
+	})
+	return diffs
+}
+
+// flattenFields sets fields of value to paths of its leaves e.g "spec.template.spec.containers[0].image"
+// and their json encoded values.
+func flattenFields(prefix string, value interface{}, fields map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			path := key
+			if len(prefix) > 0 {
+				path = prefix + "." + key
+			}
+			flattenFields(path, field, fields)
+		}
+	case []interface{}:
+		for i, item := range v {
+			flattenFields(fmt.Sprintf("%s[%d]", prefix, i), item, fields)
+		}
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			data = []byte(fmt.Sprint(v))
+		}
+		fields[prefix] = string(data)
+	}
+}
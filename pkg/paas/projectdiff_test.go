@@ -0,0 +1,55 @@
+package paas_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arvancloud/cli/pkg/cli/clitest"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+func TestDiffProjects(t *testing.T) {
+	configMap := func(mode string) []map[string]interface{} {
+		return []map[string]interface{}{{
+			"metadata": map[string]interface{}{"name": "app", "uid": mode},
+			"data":     map[string]interface{}{"MODE": mode},
+		}}
+	}
+
+	tests := []struct {
+		name       string
+		production string
+		exitCode   int
+		output     string
+	}{
+		{name: "different", production: "release", exitCode: utl.DefaultErrorExitCode, output: `data.MODE: "debug" -> "release"`},
+		{name: "same", production: "debug", output: `No differences between projects "staging" and "production".`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := clitest.New(t)
+			h.Server.Projects["at1"] = []string{"staging", "production"}
+			h.Server.Objects["at1/api/v1/namespaces/staging/configmaps"] = configMap("debug")
+			h.Server.Objects["at1/api/v1/namespaces/production/configmaps"] = configMap(test.production)
+
+			result := h.Run("", "paas", "diff-projects", "staging", "production")
+			if result.ExitCode != test.exitCode {
+				t.Fatalf("exit code = %d, want %d:\n%s", result.ExitCode, test.exitCode, result)
+			}
+			if !strings.Contains(result.Stdout, test.output) {
+				t.Errorf("output does not contain %q:\n%s", test.output, result)
+			}
+		})
+	}
+}
+
+func TestDiffProjectsNotFound(t *testing.T) {
+	h := clitest.New(t)
+	h.Server.Projects["at1"] = []string{"staging"}
+
+	result := h.Run("", "paas", "diff-projects", "staging", "production")
+	if result.ExitCode != utl.NotFoundErrorExitCode {
+		t.Fatalf("exit code = %d, want %d:\n%s", result.ExitCode, utl.NotFoundErrorExitCode, result)
+	}
+}
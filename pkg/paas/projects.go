@@ -0,0 +1,429 @@
+package paas
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arvancloud/cli/pkg/options"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+const (
+	outputFormatWide = "wide"
+
+	projectPath           = projectListPath + "/%s"
+	resourceQuotasPath    = "api/v1/namespaces/%s/resourcequotas"
+	displayNameAnnotation = "openshift.io/display-name"
+	descriptionAnnotation = "openshift.io/description"
+
+	defaultDeleteTimeout = 5 * time.Minute
+)
+
+// Project describes a paas project along with arvan specific metadata.
+type Project struct {
+	Name        string       `json:"name"`
+	DisplayName string       `json:"displayName"`
+	Description string       `json:"description"`
+	Status      string       `json:"status"`
+	Zone        string       `json:"zone"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	Quota       []QuotaUsage `json:"quota,omitempty"`
+	Migration   string       `json:"migration,omitempty"`
+}
+
+// QuotaUsage is usage of a resource limited by quotas of a project.
+type QuotaUsage struct {
+	Resource string `json:"resource"`
+	Used     string `json:"used"`
+	Hard     string `json:"hard"`
+}
+
+type projectObject struct {
+	Metadata struct {
+		Name              string            `json:"name"`
+		CreationTimestamp time.Time         `json:"creationTimestamp"`
+		Annotations       map[string]string `json:"annotations"`
+	} `json:"metadata"`
+	Status struct {
+		Phase string `json:"phase"`
+	} `json:"status"`
+}
+
+type projectObjectList struct {
+	Items []projectObject `json:"items"`
+}
+
+type resourceQuotaList struct {
+	Items []struct {
+		Status struct {
+			Hard map[string]string `json:"hard"`
+			Used map[string]string `json:"used"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// addProjectsCommands adds list, describe and delete subcommands to projects command of paasCommand.
+// Running projects command itself still switches between projects the way oc does.
+func addProjectsCommands(paasCommand *cobra.Command, o *options.Options) {
+	var projectsCommand *cobra.Command
+	for _, c := range paasCommand.Commands() {
+		if c.Name() == "projects" {
+			projectsCommand = c
+			break
+		}
+	}
+	if projectsCommand == nil {
+		projectsCommand = &cobra.Command{
+			Use:   "projects",
+			Short: "Manage projects",
+		}
+		paasCommand.AddCommand(projectsCommand)
+	}
+
+	projectsCommand.AddCommand(newCmdProjectsList(o))
+	projectsCommand.AddCommand(newCmdProjectsDescribe(o))
+	projectsCommand.AddCommand(newCmdProjectsDelete(o))
+}
+
+// newCmdProjectsList returns new cobra commad listing projects of current zone.
+func newCmdProjectsList(o *options.Options) *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List projects of current region",
+		Long: `
+    List projects of current region.
+
+    Use "-o wide" to show description, region, creation time, quota usage and migration state of
+    each project, or "-o json" in scripts.`,
+		Args: cobra.NoArgs,
+		Run: func(c *cobra.Command, args []string) {
+			o.CheckErr(validateProjectsOutputFormat(output))
+
+			projects, err := getProjects(o, output != "")
+			o.CheckErr(err)
+
+			switch output {
+			case outputFormatJSON:
+				o.CheckErr(printJSON(o.Out, projects))
+			case outputFormatWide:
+				sprintProjectsWide(o.Out, projects)
+			default:
+				sprintProjectsTable(o.Out, projects)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format. One of: wide, json")
+
+	return cmd
+}
+
+// newCmdProjectsDescribe returns new cobra commad showing details of a project.
+func newCmdProjectsDescribe(o *options.Options) *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "describe NAME",
+		Short: "Show details of a project",
+		Args:  cobra.ExactArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			o.CheckErr(validateOutputFormat(output))
+
+			project, err := getProject(o, args[0])
+			o.CheckErr(err)
+
+			if output == outputFormatJSON {
+				o.CheckErr(printJSON(o.Out, project))
+				return
+			}
+			sprintProject(o.Out, *project)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format. One of: json")
+
+	return cmd
+}
+
+// newCmdProjectsDelete returns new cobra commad deleting a project and its kubeconfig contexts.
+func newCmdProjectsDelete(o *options.Options) *cobra.Command {
+	var wait, yes bool
+	var timeout time.Duration
+	cmd := &cobra.Command{
+		Use:   "delete NAME",
+		Short: "Delete a project",
+		Long: `
+    Delete a project and remove its contexts from paas kubeconfig.
+
+    Asks to enter name of the project to confirm unless --yes is set.
+    Use --wait to wait until all resources of the project are deleted.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			name := args[0]
+
+			if !yes {
+				confirmed, err := deleteProjectConfirm(name, getCurrentRegion(o), o.In, o.Out)
+				o.CheckErr(err)
+				if !confirmed {
+					o.CheckErr(utl.Errorf(utl.KindUserAborted, "deletion aborted"))
+				}
+			}
+
+			err := paasRequest(o, http.MethodDelete, getArvanPaasServerBase(o.Config)+fmt.Sprintf(projectPath, url.PathEscape(name)), nil)
+			o.CheckErr(err)
+
+			arvanHostnamePort, err := getArvanServerDomainPort(o.Config)
+			o.CheckErr(err)
+			o.CheckErr(removeKubeConfigNamespace(paasConfigPath(o.Config), arvanHostnamePort, name))
+
+			if wait {
+				fmt.Fprintf(o.Out, "Waiting for project %q to be deleted...\n", name)
+				o.CheckErr(waitForProjectDeletion(o, name, timeout))
+			}
+
+			fmt.Fprintf(o.Out, "Project %q deleted.\n", name)
+		},
+	}
+
+	cmd.Flags().BoolVar(&yes, "yes", false, "Delete the project without asking for confirmation")
+	cmd.Flags().BoolVar(&wait, "wait", false, "Wait until the project is deleted")
+	cmd.Flags().DurationVar(&timeout, "timeout", defaultDeleteTimeout, "Maximum time to wait for the project to be deleted")
+
+	return cmd
+}
+
+// deleteProjectConfirm gets confirmation of deleting project by asking user to enter its name.
+func deleteProjectConfirm(project, currentRegion string, in io.Reader, writer io.Writer) (bool, error) {
+	explain := fmt.Sprintf("\nYou're about to delete \"%s\" from region \"%s\".\n\n"+yellowColor+"WARNING:\nAll resources and data of the project will be deleted. This can not be undone."+resetColor+"\n\n", project, currentRegion)
+
+	_, err := fmt.Fprint(writer, explain)
+	if err != nil {
+		return false, err
+	}
+	inputExplain := fmt.Sprintf("Please enter project's name [%s] to proceed: ", project)
+
+	v := confirmationValidator{project: project}
+
+	value, err := utl.ReadInput(inputExplain, "", writer, in, v.confirmationValidate)
+	return value == project, err
+}
+
+// getProjects returns projects of current zone. Quota usage and migration state are only collected if detailed is set.
+func getProjects(o *options.Options, detailed bool) ([]Project, error) {
+	var list projectObjectList
+	err := paasRequest(o, http.MethodGet, getArvanPaasServerBase(o.Config)+projectListPath, &list)
+	if err != nil {
+		return nil, err
+	}
+
+	zone := getCurrentRegion(o)
+	projects := make([]Project, 0, len(list.Items))
+	for _, item := range list.Items {
+		projects = append(projects, newProject(item, zone))
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].Name < projects[j].Name
+	})
+
+	if detailed {
+		migrating, err := getMigratingProject(o)
+		if err != nil {
+			return nil, err
+		}
+		for i := range projects {
+			projects[i].Quota, err = getQuotaUsage(o, getArvanPaasServerBase(o.Config), projects[i].Name)
+			if err != nil {
+				return nil, err
+			}
+			if migrating != nil && migrating.Namespace == projects[i].Name {
+				projects[i].Migration = string(migrating.State)
+			}
+		}
+	}
+	return projects, nil
+}
+
+// getProject returns project of current zone named name along with its quota usage and migration state.
+func getProject(o *options.Options, name string) (*Project, error) {
+	var item projectObject
+	err := paasRequest(o, http.MethodGet, getArvanPaasServerBase(o.Config)+fmt.Sprintf(projectPath, url.PathEscape(name)), &item)
+	if err != nil {
+		if utl.KindOf(err) == utl.KindNotFound {
+			return nil, utl.Errorf(utl.KindNotFound, "project %q not found in region %s", name, getCurrentRegion(o))
+		}
+		return nil, err
+	}
+
+	project := newProject(item, getCurrentRegion(o))
+	project.Quota, err = getQuotaUsage(o, getArvanPaasServerBase(o.Config), name)
+	if err != nil {
+		return nil, err
+	}
+	migrating, err := getMigratingProject(o)
+	if err != nil {
+		return nil, err
+	}
+	if migrating != nil && migrating.Namespace == name {
+		project.Migration = string(migrating.State)
+	}
+	return &project, nil
+}
+
+func newProject(item projectObject, zone string) Project {
+	return Project{
+		Name:        item.Metadata.Name,
+		DisplayName: item.Metadata.Annotations[displayNameAnnotation],
+		Description: item.Metadata.Annotations[descriptionAnnotation],
+		Status:      item.Status.Phase,
+		Zone:        zone,
+		CreatedAt:   item.Metadata.CreationTimestamp,
+	}
+}
+
+// getQuotaUsage returns usage of resources limited by quotas of project in paas api of serverBase, sorted by resource name.
+func getQuotaUsage(o *options.Options, serverBase, project string) ([]QuotaUsage, error) {
+	var quotas resourceQuotaList
+	err := paasRequest(o, http.MethodGet, serverBase+fmt.Sprintf(resourceQuotasPath, url.PathEscape(project)), &quotas)
+	if err != nil {
+		return nil, err
+	}
+
+	var usage []QuotaUsage
+	for _, quota := range quotas.Items {
+		for resource, hard := range quota.Status.Hard {
+			usage = append(usage, QuotaUsage{
+				Resource: resource,
+				Used:     quota.Status.Used[resource],
+				Hard:     hard,
+			})
+		}
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].Resource < usage[j].Resource
+	})
+	return usage, nil
+}
+
+// getMigratingProject returns the latest migration of current zone, or nil if there is none.
+func getMigratingProject(o *options.Options) (*ProgressResponse, error) {
+	return getZoneMigration(o, getCurrentRegion(o))
+}
+
+// getZoneMigration returns the latest migration from zone of region e.g "ir-thr-at1", or nil if there is none.
+func getZoneMigration(o *options.Options, region string) (*ProgressResponse, error) {
+	arvanURL, err := url.Parse(o.Config.GetServer())
+	if err != nil {
+		return nil, utl.Errorf(utl.KindValidation, "invalid config")
+	}
+	var response ProgressResponse
+	endpoint := arvanURL.Scheme + "://" + arvanURL.Host + fmt.Sprintf(migrationEndpoint, region)
+	err = paasRequest(o, http.MethodGet, endpoint, &response)
+	if utl.KindOf(err) == utl.KindNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// waitForProjectDeletion polls project until it's not found.
+func waitForProjectDeletion(o *options.Options, name string, timeout time.Duration) error {
+	deadline := o.Clock.Now().Add(timeout)
+	endpoint := getArvanPaasServerBase(o.Config) + fmt.Sprintf(projectPath, url.PathEscape(name))
+	for {
+		err := paasRequest(o, http.MethodGet, endpoint, nil)
+		if utl.KindOf(err) == utl.KindNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !o.Clock.Now().Before(deadline) {
+			return utl.Errorf(utl.KindServer, "timed out waiting for project %q to be deleted", name)
+		}
+		o.Clock.Sleep(interval * time.Second)
+	}
+}
+
+// validateProjectsOutputFormat makes sure output is either empty for human readable output, wide or json.
+func validateProjectsOutputFormat(output string) error {
+	if output != "" && output != outputFormatWide && output != outputFormatJSON {
+		return utl.Errorf(utl.KindValidation, "invalid output format %q. One of: wide, json", output)
+	}
+	return nil
+}
+
+// sprintProjectsTable displays projects in columns.
+func sprintProjectsTable(out io.Writer, projects []Project) {
+	w := new(tabwriter.Writer)
+	w.Init(out, 0, 8, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "NAME\tDISPLAY NAME\tSTATUS")
+	for _, p := range projects {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", p.Name, p.DisplayName, p.Status)
+	}
+}
+
+// sprintProjectsWide displays projects in columns including details.
+func sprintProjectsWide(out io.Writer, projects []Project) {
+	w := new(tabwriter.Writer)
+	w.Init(out, 0, 8, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "NAME\tDISPLAY NAME\tSTATUS\tREGION\tCREATED\tQUOTA\tMIGRATION\tDESCRIPTION")
+	for _, p := range projects {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", p.Name, p.DisplayName, p.Status, p.Zone,
+			p.CreatedAt.Format(time.RFC3339), sprintQuota(p.Quota), sprintValue(p.Migration), p.Description)
+	}
+}
+
+// sprintProject displays details of project in lines.
+func sprintProject(out io.Writer, p Project) {
+	w := new(tabwriter.Writer)
+	w.Init(out, 0, 8, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintf(w, "Name:\t%s\n", p.Name)
+	fmt.Fprintf(w, "Display Name:\t%s\n", p.DisplayName)
+	fmt.Fprintf(w, "Description:\t%s\n", p.Description)
+	fmt.Fprintf(w, "Status:\t%s\n", p.Status)
+	fmt.Fprintf(w, "Region:\t%s\n", p.Zone)
+	fmt.Fprintf(w, "Created:\t%s\n", p.CreatedAt.Format(time.RFC3339))
+	fmt.Fprintf(w, "Migration:\t%s\n", sprintValue(p.Migration))
+	fmt.Fprintln(w, "Quota:")
+	if len(p.Quota) == 0 {
+		fmt.Fprintln(w, "  -")
+	}
+	for _, q := range p.Quota {
+		fmt.Fprintf(w, "  %s:\t%s/%s\n", q.Resource, sprintValue(q.Used), q.Hard)
+	}
+}
+
+// sprintQuota displays quota usage in one line e.g "pods=2/10,requests.cpu=500m/2".
+func sprintQuota(quota []QuotaUsage) string {
+	if len(quota) == 0 {
+		return "-"
+	}
+	usage := make([]string, 0, len(quota))
+	for _, q := range quota {
+		usage = append(usage, fmt.Sprintf("%s=%s/%s", q.Resource, sprintValue(q.Used), q.Hard))
+	}
+	return strings.Join(usage, ",")
+}
+
+func sprintValue(value string) string {
+	if len(value) == 0 {
+		return "-"
+	}
+	return value
+}
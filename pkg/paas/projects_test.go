@@ -0,0 +1,46 @@
+package paas_test
+
+import (
+	"testing"
+
+	"github.com/arvancloud/cli/pkg/cli/clitest"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+func TestProjectsDelete(t *testing.T) {
+	tests := []struct {
+		name     string
+		stdin    string
+		args     []string
+		exitCode int
+		deleted  bool
+	}{
+		{name: "confirmed", stdin: "web\n", deleted: true},
+		{name: "wrong name then confirmed", stdin: "api\nweb\n", deleted: true},
+		{name: "yes", args: []string{"--yes"}, deleted: true},
+		{name: "not confirmed", exitCode: utl.UserAbortedErrorExitCode},
+		{name: "wrong name", stdin: "api\n", exitCode: utl.UserAbortedErrorExitCode},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := clitest.New(t)
+			h.Server.Projects["at1"] = []string{"api", "web"}
+
+			result := h.Run(test.stdin, append([]string{"paas", "projects", "delete", "web"}, test.args...)...)
+			if result.ExitCode != test.exitCode {
+				t.Fatalf("exit code = %d, want %d:\n%s", result.ExitCode, test.exitCode, result)
+			}
+
+			deleted := true
+			for _, project := range h.Server.Projects["at1"] {
+				if project == "web" {
+					deleted = false
+				}
+			}
+			if deleted != test.deleted {
+				t.Errorf("project deleted = %t, want %t", deleted, test.deleted)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package paas
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arvancloud/cli/pkg/config"
+	"github.com/arvancloud/cli/pkg/options"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+const (
+	zoneStatusUp = "UP"
+)
+
+// newCmdRegionList returns new cobra commad listing zones.
+func newCmdRegionList(o *options.Options) *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List regions",
+		Args:  cobra.NoArgs,
+		Run: func(c *cobra.Command, args []string) {
+			o.CheckErr(validateOutputFormat(output))
+
+			zones, err := getZones(o)
+			o.CheckErr(err)
+
+			if output == outputFormatJSON {
+				o.CheckErr(printJSON(o.Out, zones))
+				return
+			}
+			sprintZones(o.Out, zones, getCurrentRegion(o))
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format. One of: json")
+
+	return cmd
+}
+
+// newCmdRegionCurrent returns new cobra commad showing current zone.
+func newCmdRegionCurrent(o *options.Options) *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "current",
+		Short: "Show current region",
+		Args:  cobra.NoArgs,
+		Run: func(c *cobra.Command, args []string) {
+			o.CheckErr(validateOutputFormat(output))
+
+			currentRegion := getCurrentRegion(o)
+			if output != outputFormatJSON {
+				fmt.Fprintln(o.Out, currentRegion)
+				return
+			}
+
+			zones, err := getZones(o)
+			o.CheckErr(err)
+			zone, err := findZone(zones, currentRegion)
+			o.CheckErr(err)
+			o.CheckErr(printJSON(o.Out, zone))
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format. One of: json")
+
+	return cmd
+}
+
+// newCmdRegionUse returns new cobra commad switching to a zone without prompting.
+func newCmdRegionUse(o *options.Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "use NAME",
+		Short:   "Switch to region NAME",
+		Example: "  arvan paas region use ir-thr-at1",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) != 1 {
+				o.CheckErr(utl.Errorf(utl.KindValidation, "region name is required. See 'arvan paas region list'"))
+			}
+
+			zones, err := getZones(o)
+			o.CheckErr(err)
+			zone, err := findZone(zones, args[0])
+			o.CheckErr(err)
+			o.CheckErr(validateZoneUp(*zone))
+
+			o.CheckErr(switchRegion(o, c, *zone))
+
+			fmt.Fprintf(o.Out, "Region switched to %s successfully.\n", zoneFullName(*zone))
+		},
+	}
+
+	return cmd
+}
+
+// getZones returns zones from PaaS API.
+func getZones(o *options.Options) ([]config.Zone, error) {
+	regions, err := o.Client.GetZones()
+	if err != nil {
+		return nil, err
+	}
+	if len(regions.Zones) < 1 {
+		return nil, utl.NewError(utl.KindServer, errors.New("invalid region info"))
+	}
+	return regions.Zones, nil
+}
+
+// findZone finds a zone by its full name e.g "ir-thr-at1" or its name e.g "at1".
+func findZone(zones []config.Zone, name string) (*config.Zone, error) {
+	for i := range zones {
+		if zoneFullName(zones[i]) == name || zones[i].Name == name {
+			return &zones[i], nil
+		}
+	}
+	return nil, utl.Errorf(utl.KindNotFound, "region %q not found. See 'arvan paas region list'", name)
+}
+
+// validateZoneUp makes sure zone is UP.
+func validateZoneUp(zone config.Zone) error {
+	if zone.Status != zoneStatusUp {
+		return utl.Errorf(utl.KindValidation, "region %q is %s. Choose an UP region from 'arvan paas region list'", zoneFullName(zone), zone.Status)
+	}
+	return nil
+}
+
+// zoneFullName returns name of zone including its region e.g "ir-thr-at1".
+func zoneFullName(zone config.Zone) string {
+	return zone.RegionName + "-" + zone.Name
+}
+
+// sprintZones displays zones in columns, marking currentRegion with '*'.
+func sprintZones(out io.Writer, zones []config.Zone, currentRegion string) {
+	w := new(tabwriter.Writer)
+	w.Init(out, 0, 8, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "CURRENT\tNAME\tCITY\tCOUNTRY\tRELEASE\tVERSION\tSTATUS\tDEFAULT")
+	for _, zone := range zones {
+		current := ""
+		if zoneFullName(zone) == currentRegion {
+			current = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%t\n", current, zoneFullName(zone), zone.RegionCity, zone.RegionCountry, zone.Release, zone.Version, zone.Status, zone.Default)
+	}
+}
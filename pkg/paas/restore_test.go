@@ -0,0 +1,68 @@
+package paas_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvancloud/cli/pkg/cli/clitest"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+// exportBundle exports project "shop" of h, holding configmap "settings", and returns path of the bundle.
+func exportBundle(t *testing.T, h *clitest.Harness) string {
+	t.Helper()
+	h.Server.Projects["at1"] = []string{"shop"}
+	h.Server.Objects["at1/api/v1/namespaces/shop/configmaps"] = []map[string]interface{}{
+		{"metadata": map[string]interface{}{"name": "settings", "namespace": "shop", "uid": "1234"}, "data": map[string]interface{}{"mode": "production"}},
+	}
+
+	bundle := filepath.Join(t.TempDir(), "shop.tgz")
+	if result := h.Run("", "paas", "export", "shop", "-o", bundle); result.ExitCode != 0 {
+		t.Fatalf("export failed:\n%s", result)
+	}
+	return bundle
+}
+
+func TestRestoreProject(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		namespace string
+	}{
+		{name: "exported project", namespace: "shop"},
+		{name: "another project", args: []string{"--project", "shop-staging"}, namespace: "shop-staging"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := clitest.New(t)
+			bundle := exportBundle(t, h)
+
+			result := h.Run("", append([]string{"paas", "restore", bundle, "--dry-run"}, test.args...)...)
+			if result.ExitCode != 0 {
+				t.Fatalf("restore failed:\n%s", result)
+			}
+			expected := "metadata:\n  name: settings\n  namespace: " + test.namespace + "\n"
+			if !strings.Contains(result.Stdout, expected) {
+				t.Errorf("restored configmap is not in namespace %s:\n%s", test.namespace, result)
+			}
+			if strings.Contains(result.Stdout, "uid") {
+				t.Errorf("generated fields are restored:\n%s", result)
+			}
+		})
+	}
+}
+
+func TestRestoreMissingProject(t *testing.T) {
+	h := clitest.New(t)
+	bundle := exportBundle(t, h)
+
+	result := h.Run("", "paas", "restore", bundle, "--project", "shop-staging")
+	if result.ExitCode != utl.NotFoundErrorExitCode {
+		t.Fatalf("exit code = %d, want %d:\n%s", result.ExitCode, utl.NotFoundErrorExitCode, result)
+	}
+	if !strings.Contains(result.Stderr, `project "shop-staging" not found`) {
+		t.Errorf("missing project is not reported:\n%s", result)
+	}
+}
@@ -0,0 +1,697 @@
+package paas
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/arvancloud/cli/pkg/config"
+	"github.com/arvancloud/cli/pkg/options"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+const (
+	sealedSecretApiVersion = "arvan.ir/v1"
+	sealedSecretKind       = "SealedSecret"
+
+	sealingKeysDirName  = "sealing-keys"
+	privateKeyExtension = ".key"
+	publicKeyExtension  = ".pub"
+	sealingKeyBits      = 3072
+
+	pemPrivateKeyType = "PRIVATE KEY"
+	pemPublicKeyType  = "PUBLIC KEY"
+)
+
+var (
+	secretsSealLong = `
+    Encrypt data of a secret with the sealing key of a project, producing a manifest safe to commit
+
+    Data is read from a Secret manifest given by -f, or from a .env file given by --from-env-file.
+    Only the public key of the project is needed to seal secrets; share it by "arvan paas secrets keys export".`
+
+	secretsUnsealLong = `
+    Decrypt a sealed secret with the private sealing key of its project
+
+    The decrypted Secret is printed, or applied to the project if --apply is given.`
+
+	secretsKeysLong = `
+    Manage key pairs sealing secrets of projects
+
+    Keys are stored in the arvan config directory, e.g ~/.arvan/sealing-keys. Keep private keys safe;
+    sealed secrets can't be decrypted without them.`
+)
+
+// SealedSecret is a secret whose data is encrypted by the sealing key of its project.
+type SealedSecret struct {
+	ApiVersion string           `yaml:"apiVersion"`
+	Kind       string           `yaml:"kind"`
+	Metadata   SealedSecretMeta `yaml:"metadata"`
+	Spec       SealedSecretSpec `yaml:"spec"`
+}
+
+// SealedSecretMeta names a sealed secret and the project it's sealed for.
+type SealedSecretMeta struct {
+	Name    string `yaml:"name"`
+	Project string `yaml:"project"`
+}
+
+// SealedSecretSpec holds data of a sealed secret, encrypted by AES-GCM using a random key which is encrypted
+// by RSA-OAEP using the public key of the project.
+type SealedSecretSpec struct {
+	Type           string            `yaml:"type,omitempty"`
+	KeyFingerprint string            `yaml:"keyFingerprint"`
+	EncryptedKey   string            `yaml:"encryptedKey"`
+	EncryptedData  map[string]string `yaml:"encryptedData"`
+}
+
+// addSecretsCommands adds seal, unseal and keys subcommands to secrets command of paasCommand.
+func addSecretsCommands(paasCommand *cobra.Command, o *options.Options) {
+	var secretsCommand *cobra.Command
+	for _, c := range paasCommand.Commands() {
+		if c.Name() == "secrets" {
+			secretsCommand = c
+			break
+		}
+	}
+	if secretsCommand == nil {
+		secretsCommand = &cobra.Command{
+			Use:   "secrets",
+			Short: "Manage secrets",
+		}
+		paasCommand.AddCommand(secretsCommand)
+	}
+	if !secretsCommand.HasAlias("secret") {
+		secretsCommand.Aliases = append(secretsCommand.Aliases, "secret")
+	}
+
+	secretsCommand.AddCommand(newCmdSecretsSeal(o))
+	secretsCommand.AddCommand(newCmdSecretsUnseal(o))
+	secretsCommand.AddCommand(newCmdSecretsKeys(o))
+}
+
+// newCmdSecretsSeal returns new cobra commad encrypting a secret for a project.
+func newCmdSecretsSeal(o *options.Options) *cobra.Command {
+	var filename, envFile, name, project, output string
+	cmd := &cobra.Command{
+		Use:   "seal",
+		Short: "Encrypt a secret, producing a manifest safe to commit",
+		Long:  secretsSealLong,
+		Example: `  arvan paas secrets seal -f secret.yaml -o sealed-secret.yaml
+  arvan paas secrets seal --from-env-file .env.production --name web-env --project shop`,
+		Args: cobra.NoArgs,
+		Run: func(c *cobra.Command, args []string) {
+			if (len(filename) == 0) == (len(envFile) == 0) {
+				o.CheckErr(utl.Errorf(utl.KindValidation, "either -f or --from-env-file is required"))
+			}
+			if len(project) == 0 {
+				namespace, err := commandNamespace(c)
+				o.CheckErr(err)
+				project = namespace
+			}
+
+			secretType := ""
+			var data map[string]string
+			var err error
+			if len(filename) > 0 {
+				name, secretType, data, err = readSecretManifest(filename, name)
+			} else {
+				data, err = readEnvFile(envFile)
+			}
+			o.CheckErr(err)
+			if len(name) == 0 {
+				o.CheckErr(utl.Errorf(utl.KindValidation, "name of the secret is required. Use --name to set it"))
+			}
+
+			publicKey, err := loadPublicSealingKey(o.Config, project)
+			o.CheckErr(err)
+			sealed, err := sealSecret(publicKey, project, name, secretType, data)
+			o.CheckErr(err)
+
+			document, err := yaml.Marshal(sealed)
+			o.CheckErr(err)
+			o.CheckErr(writeOutput(o.Out, output, document))
+		},
+	}
+
+	cmd.Flags().StringVarP(&filename, "filename", "f", "", "Secret manifest to seal")
+	cmd.Flags().StringVar(&envFile, "from-env-file", "", "The .env file holding data of the secret")
+	cmd.Flags().StringVar(&name, "name", "", "Name of the secret, name of the Secret manifest by default")
+	cmd.Flags().StringVar(&project, "project", "", "Project the secret is sealed for, the current project by default")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "File to write the sealed secret to, stdout by default")
+
+	return cmd
+}
+
+// newCmdSecretsUnseal returns new cobra commad decrypting a sealed secret.
+func newCmdSecretsUnseal(o *options.Options) *cobra.Command {
+	var filename string
+	var apply bool
+	cmd := &cobra.Command{
+		Use:     "unseal",
+		Short:   "Decrypt a sealed secret, optionally applying it",
+		Long:    secretsUnsealLong,
+		Example: "  arvan paas secrets unseal -f sealed-secret.yaml --apply",
+		Args:    cobra.NoArgs,
+		Run: func(c *cobra.Command, args []string) {
+			data, err := ioutil.ReadFile(filename)
+			o.CheckErr(err)
+			var sealed SealedSecret
+			if err = yaml.UnmarshalStrict(data, &sealed); err != nil || sealed.Kind != sealedSecretKind || sealed.ApiVersion != sealedSecretApiVersion {
+				o.CheckErr(utl.Errorf(utl.KindValidation, "%s is not a sealed secret", filename))
+			}
+			o.CheckErr(validateSealingProject(sealed.Metadata.Project))
+
+			privateKey, err := loadPrivateSealingKey(o.Config, sealed.Metadata.Project)
+			o.CheckErr(err)
+			secret, err := unsealSecret(privateKey, sealed)
+			o.CheckErr(err)
+
+			if !apply {
+				document, err := yaml.Marshal(secret)
+				o.CheckErr(err)
+				_, err = o.Out.Write(document)
+				o.CheckErr(err)
+				return
+			}
+
+			secretKind, err := getBundleKind("Secret")
+			o.CheckErr(err)
+			o.CheckErr(applyProjectObject(o, getArvanPaasServerBase(o.Config), sealed.Metadata.Project, secretKind, secret))
+			fmt.Fprintf(o.Out, "Secret %q applied to project %q.\n", sealed.Metadata.Name, sealed.Metadata.Project)
+		},
+	}
+
+	cmd.Flags().StringVarP(&filename, "filename", "f", "", "Sealed secret to decrypt")
+	cmd.Flags().BoolVar(&apply, "apply", false, "Apply the decrypted secret to its project")
+	_ = cmd.MarkFlagRequired("filename")
+
+	return cmd
+}
+
+// newCmdSecretsKeys returns new cobra commad managing sealing keys of projects.
+func newCmdSecretsKeys(o *options.Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keys",
+		Short: "Manage keys sealing secrets of projects",
+		Long:  secretsKeysLong,
+	}
+
+	var force bool
+	create := &cobra.Command{
+		Use:   "create PROJECT",
+		Short: "Create the key pair sealing secrets of a project",
+		Args:  cobra.ExactArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			o.CheckErr(createSealingKey(o.Config, args[0], force))
+			fmt.Fprintf(o.Out, "Sealing key of project %q created.\n", args[0])
+		},
+	}
+	create.Flags().BoolVar(&force, "force", false, "Replace the existing key pair. Secrets sealed by it can't be unsealed anymore")
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List projects having sealing keys",
+		Args:  cobra.NoArgs,
+		Run: func(c *cobra.Command, args []string) {
+			keys, err := listSealingKeys(o.Config)
+			o.CheckErr(err)
+			w := new(tabwriter.Writer)
+			w.Init(o.Out, 0, 8, 2, ' ', 0)
+			defer w.Flush()
+			fmt.Fprintln(w, "PROJECT\tPRIVATE KEY\tFINGERPRINT")
+			for _, key := range keys {
+				fmt.Fprintf(w, "%s\t%t\t%s\n", key.project, key.private, key.fingerprint)
+			}
+		},
+	}
+
+	export := &cobra.Command{
+		Use:   "export PROJECT",
+		Short: "Print the public key of a project, to let others seal its secrets",
+		Args:  cobra.ExactArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			publicKey, err := loadPublicSealingKey(o.Config, args[0])
+			o.CheckErr(err)
+			data, err := encodePublicKey(publicKey)
+			o.CheckErr(err)
+			_, err = o.Out.Write(data)
+			o.CheckErr(err)
+		},
+	}
+
+	var keyFile string
+	var forceImport bool
+	importCmd := &cobra.Command{
+		Use:   "import PROJECT",
+		Short: "Import the public or private key of a project",
+		Args:  cobra.ExactArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			o.CheckErr(importSealingKey(o.Config, args[0], keyFile, forceImport))
+			fmt.Fprintf(o.Out, "Sealing key of project %q imported.\n", args[0])
+		},
+	}
+	importCmd.Flags().StringVarP(&keyFile, "filename", "f", "", "PEM file of the key")
+	importCmd.Flags().BoolVar(&forceImport, "force", false, "Import a public key of another pair than the existing private key, removing the private key")
+	_ = importCmd.MarkFlagRequired("filename")
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete PROJECT",
+		Short: "Delete the key pair of a project. Secrets sealed by it can't be unsealed anymore",
+		Args:  cobra.ExactArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			o.CheckErr(validateSealingProject(args[0]))
+			removed := false
+			for _, path := range []string{sealingKeyPath(o.Config, args[0], privateKeyExtension), sealingKeyPath(o.Config, args[0], publicKeyExtension)} {
+				err := os.Remove(path)
+				if err == nil {
+					removed = true
+				} else if !os.IsNotExist(err) {
+					o.CheckErr(err)
+				}
+			}
+			if !removed {
+				o.CheckErr(utl.Errorf(utl.KindNotFound, "no sealing key for project %q", args[0]))
+			}
+			fmt.Fprintf(o.Out, "Sealing key of project %q deleted.\n", args[0])
+		},
+	}
+
+	cmd.AddCommand(create, list, export, importCmd, deleteCmd)
+
+	return cmd
+}
+
+// readSecretManifest returns name, type and decoded data of Secret manifest in path. name overrides name of the manifest if not empty.
+func readSecretManifest(path, name string) (string, string, map[string]string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", "", nil, utl.NewError(utl.KindValidation, err)
+	}
+	var secret struct {
+		Kind     string `yaml:"kind"`
+		Metadata struct {
+			Name string `yaml:"name"`
+		} `yaml:"metadata"`
+		Type       string            `yaml:"type"`
+		Data       map[string]string `yaml:"data"`
+		StringData map[string]string `yaml:"stringData"`
+	}
+	if err = yaml.Unmarshal(content, &secret); err != nil || secret.Kind != "Secret" {
+		return "", "", nil, utl.Errorf(utl.KindValidation, "%s is not a Secret manifest", path)
+	}
+
+	data := map[string]string{}
+	for key, value := range secret.Data {
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return "", "", nil, utl.Errorf(utl.KindValidation, "invalid base64 value of %q in %s", key, path)
+		}
+		data[key] = string(decoded)
+	}
+	for key, value := range secret.StringData {
+		data[key] = value
+	}
+	if len(name) == 0 {
+		name = secret.Metadata.Name
+	}
+	return name, secret.Type, data, nil
+}
+
+// readEnvFile returns variables of .env file in path.
+func readEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, utl.NewError(utl.KindValidation, err)
+	}
+	defer file.Close()
+	return parseEnvFile(file)
+}
+
+// writeOutput writes data to path, or out if path is empty.
+func writeOutput(out io.Writer, path string, data []byte) error {
+	if len(path) == 0 {
+		_, err := out.Write(data)
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// sealSecret encrypts data of secret named name of project using publicKey of the project.
+// Each value is bound to project, name and its key, so it can't be moved to another secret.
+func sealSecret(publicKey *rsa.PublicKey, project, name, secretType string, data map[string]string) (*SealedSecret, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, key, []byte(project))
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint, err := sealingKeyFingerprint(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := &SealedSecret{
+		ApiVersion: sealedSecretApiVersion,
+		Kind:       sealedSecretKind,
+		Metadata:   SealedSecretMeta{Name: name, Project: project},
+		Spec: SealedSecretSpec{
+			Type:           secretType,
+			KeyFingerprint: fingerprint,
+			EncryptedKey:   base64.StdEncoding.EncodeToString(encryptedKey),
+			EncryptedData:  map[string]string{},
+		},
+	}
+	for dataKey, value := range data {
+		nonce := make([]byte, gcm.NonceSize())
+		if _, err = rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		encrypted := gcm.Seal(nonce, nonce, []byte(value), sealingAdditionalData(project, name, dataKey))
+		sealed.Spec.EncryptedData[dataKey] = base64.StdEncoding.EncodeToString(encrypted)
+	}
+	return sealed, nil
+}
+
+// unsealSecret decrypts sealed using privateKey of its project, returning a Secret object.
+func unsealSecret(privateKey *rsa.PrivateKey, sealed SealedSecret) (object, error) {
+	fingerprint, err := sealingKeyFingerprint(&privateKey.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if fingerprint != sealed.Spec.KeyFingerprint {
+		return nil, utl.Errorf(utl.KindValidation, "secret %q is sealed by key %s, not the key of project %q", sealed.Metadata.Name, sealed.Spec.KeyFingerprint, sealed.Metadata.Project)
+	}
+
+	encryptedKey, err := base64.StdEncoding.DecodeString(sealed.Spec.EncryptedKey)
+	if err != nil {
+		return nil, utl.Errorf(utl.KindValidation, "invalid encrypted key of secret %q", sealed.Metadata.Name)
+	}
+	key, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, privateKey, encryptedKey, []byte(sealed.Metadata.Project))
+	if err != nil {
+		return nil, utl.Errorf(utl.KindValidation, "could not decrypt key of secret %q: %v", sealed.Metadata.Name, err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]interface{}{}
+	for dataKey, value := range sealed.Spec.EncryptedData {
+		encrypted, err := base64.StdEncoding.DecodeString(value)
+		if err != nil || len(encrypted) < gcm.NonceSize() {
+			return nil, utl.Errorf(utl.KindValidation, "invalid encrypted value of %q in secret %q", dataKey, sealed.Metadata.Name)
+		}
+		nonce, ciphertext := encrypted[:gcm.NonceSize()], encrypted[gcm.NonceSize():]
+		decrypted, err := gcm.Open(nil, nonce, ciphertext, sealingAdditionalData(sealed.Metadata.Project, sealed.Metadata.Name, dataKey))
+		if err != nil {
+			return nil, utl.Errorf(utl.KindValidation, "could not decrypt %q of secret %q: %v", dataKey, sealed.Metadata.Name, err)
+		}
+		data[dataKey] = base64.StdEncoding.EncodeToString(decrypted)
+	}
+
+	secretType := sealed.Spec.Type
+	if len(secretType) == 0 {
+		secretType = "Opaque"
+	}
+	return object{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": sealed.Metadata.Name, "namespace": sealed.Metadata.Project},
+		"type":       secretType,
+		"data":       data,
+	}, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func sealingAdditionalData(project, name, key string) []byte {
+	return []byte(project + "/" + name + "/" + key)
+}
+
+// sealingKeyFingerprint returns sha256 of public key e.g "sha256:1f2e...".
+func sealingKeyFingerprint(publicKey *rsa.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// validateSealingProject returns a validation error if project is not a valid project name, so it's safe
+// to be joined to paths of sealing keys.
+func validateSealingProject(project string) error {
+	if !manifestNameRegexp.MatchString(project) {
+		return utl.Errorf(utl.KindValidation, "invalid project name %q", project)
+	}
+	return nil
+}
+
+// sealingKeyPath returns path of key of project with extension in arvan config directory. project should be
+// validated by validateSealingProject.
+func sealingKeyPath(arvanConfig *config.ConfigInfo, project, extension string) string {
+	return filepath.Join(arvanConfig.GetHomeDir(), sealingKeysDirName, project+extension)
+}
+
+// createSealingKey generates the key pair of project. Existing keys are only replaced if force is set.
+func createSealingKey(arvanConfig *config.ConfigInfo, project string, force bool) error {
+	if err := validateSealingProject(project); err != nil {
+		return err
+	}
+	if _, err := os.Stat(sealingKeyPath(arvanConfig, project, publicKeyExtension)); err == nil && !force {
+		return utl.Errorf(utl.KindValidation, "project %q already has a sealing key. Use --force to replace it", project)
+	}
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, sealingKeyBits)
+	if err != nil {
+		return err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return err
+	}
+	publicData, err := encodePublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return err
+	}
+	return writeSealingKeys(arvanConfig, project, pem.EncodeToMemory(&pem.Block{Type: pemPrivateKeyType, Bytes: der}), publicData)
+}
+
+// importSealingKey imports PEM key in path as the key of project. A private key also replaces the public key.
+// A public key of another pair than the existing private key of project is only imported if force is set,
+// removing the private key.
+func importSealingKey(arvanConfig *config.ConfigInfo, project, path string, force bool) error {
+	if err := validateSealingProject(project); err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return utl.NewError(utl.KindValidation, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return utl.Errorf(utl.KindValidation, "%s is not a PEM file", path)
+	}
+
+	switch block.Type {
+	case pemPrivateKeyType:
+		privateKey, err := parsePrivateKey(block.Bytes)
+		if err != nil {
+			return utl.Errorf(utl.KindValidation, "invalid private key in %s: %v", path, err)
+		}
+		publicData, err := encodePublicKey(&privateKey.PublicKey)
+		if err != nil {
+			return err
+		}
+		return writeSealingKeys(arvanConfig, project, data, publicData)
+	case pemPublicKeyType:
+		publicKey, err := parsePublicKey(block.Bytes)
+		if err != nil {
+			return utl.Errorf(utl.KindValidation, "invalid public key in %s: %v", path, err)
+		}
+		if err = removeOtherPrivateSealingKey(arvanConfig, project, publicKey, force); err != nil {
+			return err
+		}
+		return writeSealingKeys(arvanConfig, project, nil, data)
+	}
+	return utl.Errorf(utl.KindValidation, "unsupported PEM type %q in %s", block.Type, path)
+}
+
+// removeOtherPrivateSealingKey removes the private key of project if it's not of the pair of publicKey, so secrets
+// sealed by publicKey are not unsealed by it. It fails unless force is set.
+func removeOtherPrivateSealingKey(arvanConfig *config.ConfigInfo, project string, publicKey *rsa.PublicKey, force bool) error {
+	privateKey, err := loadPrivateSealingKey(arvanConfig, project)
+	if utl.KindOf(err) == utl.KindNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if privateKey.PublicKey.Equal(publicKey) {
+		return nil
+	}
+
+	if !force {
+		fingerprint, err := sealingKeyFingerprint(&privateKey.PublicKey)
+		if err != nil {
+			return err
+		}
+		return utl.Errorf(utl.KindValidation, "project %q has the private key of another pair with fingerprint %s. "+
+			"Use --force to remove it, secrets sealed by it can't be unsealed anymore", project, fingerprint)
+	}
+	return os.Remove(sealingKeyPath(arvanConfig, project, privateKeyExtension))
+}
+
+// writeSealingKeys writes keys of project. privateData is skipped if nil.
+func writeSealingKeys(arvanConfig *config.ConfigInfo, project string, privateData, publicData []byte) error {
+	if err := os.MkdirAll(filepath.Join(arvanConfig.GetHomeDir(), sealingKeysDirName), 0700); err != nil {
+		return err
+	}
+	if privateData != nil {
+		if err := ioutil.WriteFile(sealingKeyPath(arvanConfig, project, privateKeyExtension), privateData, 0600); err != nil {
+			return err
+		}
+	}
+	return ioutil.WriteFile(sealingKeyPath(arvanConfig, project, publicKeyExtension), publicData, 0644)
+}
+
+// loadPublicSealingKey reads the public key of project.
+func loadPublicSealingKey(arvanConfig *config.ConfigInfo, project string) (*rsa.PublicKey, error) {
+	block, err := readSealingKey(arvanConfig, project, publicKeyExtension)
+	if err != nil {
+		return nil, err
+	}
+	return parsePublicKey(block.Bytes)
+}
+
+// loadPrivateSealingKey reads the private key of project.
+func loadPrivateSealingKey(arvanConfig *config.ConfigInfo, project string) (*rsa.PrivateKey, error) {
+	block, err := readSealingKey(arvanConfig, project, privateKeyExtension)
+	if err != nil {
+		return nil, err
+	}
+	return parsePrivateKey(block.Bytes)
+}
+
+func readSealingKey(arvanConfig *config.ConfigInfo, project, extension string) (*pem.Block, error) {
+	if err := validateSealingProject(project); err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(sealingKeyPath(arvanConfig, project, extension))
+	if os.IsNotExist(err) {
+		kind := "public"
+		if extension == privateKeyExtension {
+			kind = "private"
+		}
+		return nil, utl.Errorf(utl.KindNotFound, "no %s sealing key for project %q. Create one by \"arvan paas secrets keys create %s\" or import it", kind, project, project)
+	}
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, utl.Errorf(utl.KindValidation, "invalid sealing key of project %q", project)
+	}
+	return block, nil
+}
+
+func parsePrivateKey(der []byte) (*rsa.PrivateKey, error) {
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("not an RSA key")
+	}
+	return privateKey, nil
+}
+
+func parsePublicKey(der []byte) (*rsa.PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("not an RSA key")
+	}
+	return publicKey, nil
+}
+
+func encodePublicKey(publicKey *rsa.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemPublicKeyType, Bytes: der}), nil
+}
+
+type sealingKeyInfo struct {
+	project     string
+	private     bool
+	fingerprint string
+}
+
+// listSealingKeys returns keys of projects sorted by project name.
+func listSealingKeys(arvanConfig *config.ConfigInfo) ([]sealingKeyInfo, error) {
+	files, err := ioutil.ReadDir(filepath.Join(arvanConfig.GetHomeDir(), sealingKeysDirName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []sealingKeyInfo
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), publicKeyExtension) {
+			continue
+		}
+		project := strings.TrimSuffix(file.Name(), publicKeyExtension)
+		info := sealingKeyInfo{project: project, fingerprint: "-"}
+		if publicKey, err := loadPublicSealingKey(arvanConfig, project); err == nil {
+			if fingerprint, err := sealingKeyFingerprint(publicKey); err == nil {
+				info.fingerprint = fingerprint
+			}
+		}
+		if _, err := os.Stat(sealingKeyPath(arvanConfig, project, privateKeyExtension)); err == nil {
+			info.private = true
+		}
+		keys = append(keys, info)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].project < keys[j].project
+	})
+	return keys, nil
+}
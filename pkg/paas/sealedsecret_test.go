@@ -0,0 +1,163 @@
+package paas
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/arvancloud/cli/pkg/config"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+func TestSealUnsealSecret(t *testing.T) {
+	arvanConfig := config.NewConfigInfo(t.TempDir())
+	if err := createSealingKey(arvanConfig, "shop", false); err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := loadPublicSealingKey(arvanConfig, "shop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey, err := loadPrivateSealingKey(arvanConfig, "shop")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := map[string]string{"DB_PASSWORD": "s3cr3t", "EMPTY": "", "CERT": "line 1\nline 2"}
+	sealed, err := sealSecret(publicKey, "shop", "web-env", "", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range sealed.Spec.EncryptedData {
+		if value == data[key] || value == base64.StdEncoding.EncodeToString([]byte(data[key])) {
+			t.Errorf("value of %s is not encrypted", key)
+		}
+	}
+
+	secret, err := unsealSecret(privateKey, *sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedData := map[string]interface{}{}
+	for key, value := range data {
+		expectedData[key] = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	expected := object{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "web-env", "namespace": "shop"},
+		"type":       "Opaque",
+		"data":       expectedData,
+	}
+	if !reflect.DeepEqual(secret, expected) {
+		t.Errorf("unsealed secret = %v, want %v", secret, expected)
+	}
+
+	// values are bound to the secret they're sealed for
+	moved := *sealed
+	moved.Metadata.Name = "other"
+	if _, err = unsealSecret(privateKey, moved); err == nil {
+		t.Error("expected an error unsealing a value moved to another secret")
+	}
+}
+
+func TestSealingKeyOfInvalidProject(t *testing.T) {
+	arvanConfig := config.NewConfigInfo(t.TempDir())
+	for _, project := range []string{"../shop", "shop/../../x", "", "Shop"} {
+		if _, err := loadPrivateSealingKey(arvanConfig, project); utl.KindOf(err) != utl.KindValidation {
+			t.Errorf("loading private key of %q: error = %v, want a validation error", project, err)
+		}
+		if _, err := loadPublicSealingKey(arvanConfig, project); utl.KindOf(err) != utl.KindValidation {
+			t.Errorf("loading public key of %q: error = %v, want a validation error", project, err)
+		}
+	}
+}
+
+func TestImportPublicSealingKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		pair     string
+		force    bool
+		err      bool
+		private  bool
+		imported bool
+	}{
+		{name: "same pair", pair: "shop", private: true, imported: true},
+		{name: "other pair", pair: "other", err: true, private: true},
+		{name: "other pair forced", pair: "other", force: true, imported: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			arvanConfig := config.NewConfigInfo(t.TempDir())
+			for _, project := range []string{"shop", "other"} {
+				if err := createSealingKey(arvanConfig, project, false); err != nil {
+					t.Fatal(err)
+				}
+			}
+			existing, err := loadPublicSealingKey(arvanConfig, "shop")
+			if err != nil {
+				t.Fatal(err)
+			}
+			imported, err := loadPublicSealingKey(arvanConfig, test.pair)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := encodePublicKey(imported)
+			if err != nil {
+				t.Fatal(err)
+			}
+			keyFile := filepath.Join(t.TempDir(), "shop.pub")
+			if err = ioutil.WriteFile(keyFile, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			err = importSealingKey(arvanConfig, "shop", keyFile, test.force)
+			if test.err && utl.KindOf(err) != utl.KindValidation {
+				t.Errorf("error = %v, want a validation error", err)
+			} else if !test.err && err != nil {
+				t.Fatal(err)
+			}
+
+			publicKey, err := loadPublicSealingKey(arvanConfig, "shop")
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := existing
+			if test.imported {
+				expected = imported
+			}
+			if !publicKey.Equal(expected) {
+				t.Errorf("public key is imported = %t, want %t", publicKey.Equal(imported), test.imported)
+			}
+
+			privateKey, err := loadPrivateSealingKey(arvanConfig, "shop")
+			if test.private && (err != nil || !privateKey.PublicKey.Equal(existing)) {
+				t.Errorf("private key is replaced (error %v), want the existing private key to be kept", err)
+			}
+			if !test.private && utl.KindOf(err) != utl.KindNotFound {
+				t.Errorf("error loading private key = %v, want the private key to be removed", err)
+			}
+		})
+	}
+}
+
+func TestImportPublicSealingKeyWithoutPrivateKey(t *testing.T) {
+	arvanConfig := config.NewConfigInfo(t.TempDir())
+	if err := createSealingKey(arvanConfig, "other", false); err != nil {
+		t.Fatal(err)
+	}
+
+	err := importSealingKey(arvanConfig, "shop", sealingKeyPath(arvanConfig, "other", publicKeyExtension), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = loadPublicSealingKey(arvanConfig, "shop"); err != nil {
+		t.Errorf("public key is not imported: %v", err)
+	}
+	if _, err = loadPrivateSealingKey(arvanConfig, "shop"); utl.KindOf(err) != utl.KindNotFound {
+		t.Errorf("error loading private key = %v, want no private key", err)
+	}
+}
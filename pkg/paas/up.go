@@ -0,0 +1,334 @@
+package paas
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arvancloud/cli/pkg/options"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+var (
+	upLong = `
+    Build and deploy sources of a local directory
+
+    Creates a BuildConfig and an ImageStream building the directory if missing, along with a Deployment
+    running the built image. Then uploads the directory, excluding files matched by .gitignore or
+    .arvanignore, starts a build streaming its logs and waits for the deployment to roll out.`
+
+	invalidNameCharacters = regexp.MustCompile(`[^a-z0-9-]+`)
+)
+
+// ignoreFiles list patterns of files not uploaded by "arvan paas up", in .gitignore format
+var ignoreFiles = []string{".gitignore", ".arvanignore"}
+
+// NewCmdUp returns new cobra commad building and deploying a local directory using binary builds.
+func NewCmdUp(o *options.Options) *cobra.Command {
+	var name, builder string
+	var port int
+	cmd := &cobra.Command{
+		Use:   "up [DIR]",
+		Short: "Build and deploy a local directory",
+		Long:  upLong,
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			if !isLocalDirectory(dir) {
+				o.CheckErr(utl.Errorf(utl.KindValidation, "%q is not a directory", dir))
+			}
+
+			if len(name) == 0 {
+				absDir, err := filepath.Abs(dir)
+				o.CheckErr(err)
+				name = appName(filepath.Base(absDir))
+			}
+			if !manifestNameRegexp.MatchString(name) {
+				o.CheckErr(utl.Errorf(utl.KindValidation, "invalid name %q. Use --name to set a name consisting of lower case alphanumeric characters or '-'", name))
+			}
+
+			selected, err := upBuilder(dir, builder)
+			o.CheckErr(err)
+			fmt.Fprintf(o.ErrOut, "Using builder: %s\n", selected)
+
+			manifest := upManifest(name, selected, port)
+			objects, err := manifest.render()
+			o.CheckErr(err)
+			data, err := marshalObjects(objects)
+			o.CheckErr(err)
+			o.CheckErr(applyObjects(c.Parent(), data))
+
+			archive, err := ioutil.TempFile("", "arvan-up-*.tar.gz")
+			o.CheckErr(err)
+			defer os.Remove(archive.Name())
+			err = archiveDir(dir, archive)
+			if closeErr := archive.Close(); err == nil {
+				err = closeErr
+			}
+			o.CheckErr(err)
+
+			o.CheckErr(runSubcommand(c.Parent(), "start-build", name, "--from-archive", archive.Name(), "--follow", "--wait"))
+			o.CheckErr(runSubcommand(c.Parent(), "rollout", "status", "deployment/"+name))
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "Name of the application, name of the directory by default")
+	cmd.Flags().StringVar(&builder, arvanBuilderFlag, arvanBuilderAuto, "Builder of the directory. Either auto, or an image stream e.g python:3.9. "+
+		"'auto' uses the builder of the detected language, or ArvanBuilder")
+	cmd.Flags().IntVar(&port, "port", 0, "Port the application listens on, to create a service for it")
+
+	return cmd
+}
+
+// upBuilder returns image stream tag building dir selected by --arvan-builder flag value builder.
+func upBuilder(dir, builder string) (string, error) {
+	switch builder {
+	case arvanBuilderNone:
+		return "", utl.Errorf(utl.KindValidation, "a builder is required to build %q", dir)
+	case arvanBuilderAuto:
+		builder = detectBuilder(dir)
+		if len(builder) == 0 {
+			builder = arvanBuilderImage
+		}
+	}
+	return imageStreamTag(builder), nil
+}
+
+// upManifest returns manifest of an application named name, built from binary sources by builder.
+func upManifest(name, builder string, port int) Manifest {
+	return Manifest{
+		ApiVersion: manifestApiVersion,
+		Name:       name,
+		Services: []ManifestService{{
+			Name:  name,
+			Build: &ManifestBuild{Binary: true, Builder: builder},
+			Port:  port,
+		}},
+	}
+}
+
+// appName returns name of an application deployed from directory dirName, e.g "my-app" for "My_App".
+func appName(dirName string) string {
+	return strings.Trim(invalidNameCharacters.ReplaceAllString(strings.ToLower(dirName), "-"), "-")
+}
+
+// archiveDir writes files of dir to w as a gzipped tar, excluding .git and files matched by ignoreFiles of dir and
+// its subdirectories.
+func archiveDir(dir string, w io.Writer) error {
+	ignored, err := loadIgnorePatterns(dir, "")
+	if err != nil {
+		return err
+	}
+
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	err = filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, filePath)
+		if err != nil || relPath == "." {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if relPath == ".git" || ignored.matches(relPath, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			// symlinks, sockets and devices are not uploaded
+			return nil
+		}
+		if info.IsDir() {
+			// patterns of nested ignore files only match paths under their directory
+			nested, err := loadIgnorePatterns(dir, relPath)
+			if err != nil {
+				return err
+			}
+			ignored = append(ignored, nested...)
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = relPath
+		if err = tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(tarWriter, file)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+// ignorePattern is a pattern of a .gitignore file.
+type ignorePattern struct {
+	// base is the directory of the ignore file relative to the archived directory, empty for the top level
+	base string
+	// segments of the pattern separated by '/', "**" matching any number of directories
+	segments []string
+	// negated re-includes paths excluded by previous patterns, e.g "!build/keep"
+	negated bool
+	// dirOnly matches directories only, e.g "build/"
+	dirOnly bool
+	// anchored matches paths relative to base, e.g "/build" or "docs/build", rather than names in any level
+	anchored bool
+}
+
+// ignorePatterns of ignore files in the order they are read, the last matching pattern deciding whether a path is
+// ignored.
+type ignorePatterns []ignorePattern
+
+// loadIgnorePatterns reads patterns of ignoreFiles in directory base of dir. Missing files are skipped.
+func loadIgnorePatterns(dir, base string) (ignorePatterns, error) {
+	var patterns ignorePatterns
+	for _, name := range ignoreFiles {
+		fileName := path.Join(base, name)
+		file, err := os.Open(filepath.Join(dir, filepath.FromSlash(fileName)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if len(line) == 0 || strings.HasPrefix(line, "#") {
+				continue
+			}
+			p, err := parseIgnorePattern(base, line)
+			if err != nil {
+				file.Close()
+				return nil, utl.Errorf(utl.KindValidation, "invalid pattern %q in %s: %v", line, fileName, err)
+			}
+			patterns = append(patterns, p)
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return patterns, nil
+}
+
+// parseIgnorePattern parses a non-comment line of an ignore file in directory base.
+func parseIgnorePattern(base, line string) (ignorePattern, error) {
+	p := ignorePattern{base: base}
+	if strings.HasPrefix(line, "!") {
+		p.negated = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\#`) || strings.HasPrefix(line, `\!`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		p.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if len(line) == 0 {
+		return p, fmt.Errorf("empty pattern")
+	}
+
+	p.segments = strings.Split(line, "/")
+	for _, segment := range p.segments {
+		if _, err := path.Match(segment, ""); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// matches reports whether relPath, relative to the archived directory and separated by '/', is matched by p.
+func (p ignorePattern) matches(relPath string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	if len(p.base) > 0 {
+		if !strings.HasPrefix(relPath, p.base+"/") {
+			return false
+		}
+		relPath = strings.TrimPrefix(relPath, p.base+"/")
+	}
+	names := strings.Split(relPath, "/")
+	if !p.anchored {
+		matched, _ := path.Match(p.segments[0], names[len(names)-1])
+		return matched
+	}
+	return matchSegments(p.segments, names)
+}
+
+// matchSegments reports whether names of a path are matched by pattern segments. A "**" segment matches any number
+// of names, or at least one if it is the last segment, e.g "build/**" matches files in build but not build itself.
+func matchSegments(segments, names []string) bool {
+	if len(segments) == 0 {
+		return len(names) == 0
+	}
+	if segments[0] == "**" {
+		if len(segments) == 1 {
+			return len(names) > 0
+		}
+		for i := 0; i <= len(names); i++ {
+			if matchSegments(segments[1:], names[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(names) == 0 {
+		return false
+	}
+	matched, _ := path.Match(segments[0], names[0])
+	return matched && matchSegments(segments[1:], names[1:])
+}
+
+// matches reports whether relPath, relative to the archived directory and separated by '/', is ignored. As in git,
+// a path excluded by a directory is not visited, so it can not be re-included by a negated pattern.
+func (patterns ignorePatterns) matches(relPath string, isDir bool) bool {
+	ignored := false
+	for _, p := range patterns {
+		if p.matches(relPath, isDir) {
+			ignored = !p.negated
+		}
+	}
+	return ignored
+}
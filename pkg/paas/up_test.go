@@ -0,0 +1,236 @@
+package paas
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// findObject returns the first of objects of kind, failing the test if there is none.
+func findObject(t *testing.T, objects []object, kind string) object {
+	t.Helper()
+	for _, o := range objects {
+		if o["kind"] == kind {
+			return o
+		}
+	}
+	t.Fatalf("no %s in rendered objects %v", kind, objects)
+	return nil
+}
+
+func TestUpBuildConfig(t *testing.T) {
+	nodejsDir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(nodejsDir, "package.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	unknownDir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		dir     string
+		builder string
+		from    string
+	}{
+		{name: "detected", dir: nodejsDir, builder: arvanBuilderAuto, from: "nodejs:latest"},
+		{name: "undetected", dir: unknownDir, builder: arvanBuilderAuto, from: arvanBuilderImage},
+		{name: "image stream", dir: unknownDir, builder: "python", from: "python:latest"},
+		{name: "image stream tag", dir: nodejsDir, builder: "python:3.9", from: "python:3.9"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			builder, err := upBuilder(test.dir, test.builder)
+			if err != nil {
+				t.Fatal(err)
+			}
+			manifest := upManifest("app", builder, 8080)
+			objects, err := manifest.render()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			spec := findObject(t, objects, "BuildConfig")["spec"].(object)
+			from := spec["strategy"].(object)["sourceStrategy"].(object)["from"].(object)
+			expectedFrom := object{"kind": "ImageStreamTag", "namespace": builderNamespace, "name": test.from}
+			for key, value := range expectedFrom {
+				if from[key] != value {
+					t.Errorf("strategy from %s = %v, want %v", key, from[key], value)
+				}
+			}
+
+			if source := spec["source"].(object); source["type"] != "Binary" {
+				t.Errorf("source type = %v, want Binary", source["type"])
+			}
+			to := spec["output"].(object)["to"].(object)
+			if to["kind"] != "ImageStreamTag" || to["name"] != "app:latest" {
+				t.Errorf("output = %v, want ImageStreamTag app:latest", to)
+			}
+		})
+	}
+}
+
+func TestUpBuilderNone(t *testing.T) {
+	if _, err := upBuilder(t.TempDir(), arvanBuilderNone); err == nil {
+		t.Error("expected an error building without a builder")
+	}
+}
+
+func TestIgnorePatternsMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		relPath  string
+		isDir    bool
+		expected bool
+	}{
+		{name: "name at any level", lines: []string{"*.log"}, relPath: "logs/app.log", expected: true},
+		{name: "name not matched", lines: []string{"*.log"}, relPath: "app.go"},
+		{name: "directory only", lines: []string{"build/"}, relPath: "build", isDir: true, expected: true},
+		{name: "directory only file", lines: []string{"build/"}, relPath: "build"},
+		{name: "anchored", lines: []string{"/build"}, relPath: "build", isDir: true, expected: true},
+		{name: "anchored nested", lines: []string{"/build"}, relPath: "src/build", isDir: true},
+		{name: "anchored path", lines: []string{"docs/build"}, relPath: "docs/build", isDir: true, expected: true},
+		{name: "leading double star", lines: []string{"**/cache"}, relPath: "a/b/cache", isDir: true, expected: true},
+		{name: "leading double star top", lines: []string{"**/cache"}, relPath: "cache", isDir: true, expected: true},
+		{name: "middle double star", lines: []string{"a/**/b.txt"}, relPath: "a/x/y/b.txt", expected: true},
+		{name: "middle double star empty", lines: []string{"a/**/b.txt"}, relPath: "a/b.txt", expected: true},
+		{name: "trailing double star", lines: []string{"tmp/**"}, relPath: "tmp/x/y", expected: true},
+		{name: "trailing double star directory", lines: []string{"tmp/**"}, relPath: "tmp", isDir: true},
+		{name: "negated", lines: []string{"*.log", "!keep.log"}, relPath: "keep.log"},
+		{name: "negated then excluded", lines: []string{"!keep.log", "*.log"}, relPath: "keep.log", expected: true},
+		{name: "escaped", lines: []string{`\!important`}, relPath: "!important", expected: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var patterns ignorePatterns
+			for _, line := range test.lines {
+				p, err := parseIgnorePattern("", line)
+				if err != nil {
+					t.Fatal(err)
+				}
+				patterns = append(patterns, p)
+			}
+			if matched := patterns.matches(test.relPath, test.isDir); matched != test.expected {
+				t.Errorf("%v matches %q = %t, want %t", test.lines, test.relPath, matched, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseIgnorePatternInvalid(t *testing.T) {
+	for _, line := range []string{"[a-", "!", "/"} {
+		if _, err := parseIgnorePattern("", line); err == nil {
+			t.Errorf("expected an error parsing %q", line)
+		}
+	}
+}
+
+// archivedNames returns names of files archived by archiveDir writing files to a temporary directory.
+func archivedNames(t *testing.T, files map[string]string) ([]string, error) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var archive bytes.Buffer
+	if err := archiveDir(dir, &archive); err != nil {
+		return nil, err
+	}
+	gzipReader, err := gzip.NewReader(&archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+	var names []string
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			names = append(names, header.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func TestArchiveDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected []string
+	}{
+		{
+			name:     "git directory",
+			files:    map[string]string{"app.go": "", ".git/HEAD": ""},
+			expected: []string{"app.go"},
+		},
+		{
+			name:     "gitignore and arvanignore",
+			files:    map[string]string{".gitignore": "*.log\n", ".arvanignore": "# secrets\n.env\n", "app.go": "", "app.log": "", ".env": ""},
+			expected: []string{".arvanignore", ".gitignore", "app.go"},
+		},
+		{
+			name:     "negated",
+			files:    map[string]string{".gitignore": "*.log\n!keep.log\n", "app.log": "", "keep.log": ""},
+			expected: []string{".gitignore", "keep.log"},
+		},
+		{
+			name:     "excluded directory",
+			files:    map[string]string{".gitignore": "build/\n!build/keep\n", "build/keep": "", "src/build/out": "", "app.go": ""},
+			expected: []string{".gitignore", "app.go"},
+		},
+		{
+			name:     "double star",
+			files:    map[string]string{".gitignore": "**/cache/**\n", "cache/a": "", "src/cache/b": "", "src/main.go": ""},
+			expected: []string{".gitignore", "src/main.go"},
+		},
+		{
+			name: "nested ignore file",
+			files: map[string]string{
+				"web/.gitignore": "/dist\n*.map\n", "web/dist/app.js": "", "web/src/app.js": "", "web/src/app.js.map": "",
+				"dist/app.js": "", "app.js.map": "",
+			},
+			expected: []string{"app.js.map", "dist/app.js", "web/.gitignore", "web/src/app.js"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			names, err := archivedNames(t, test.files)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(names, test.expected) {
+				t.Errorf("archived files = %v, want %v", names, test.expected)
+			}
+		})
+	}
+}
+
+func TestArchiveDirInvalidPattern(t *testing.T) {
+	_, err := archivedNames(t, map[string]string{"src/.arvanignore": "[a-\n", "src/main.go": ""})
+	if err == nil || !strings.Contains(err.Error(), "src/.arvanignore") {
+		t.Errorf("error = %v, want invalid pattern of src/.arvanignore", err)
+	}
+}
@@ -0,0 +1,152 @@
+package paas
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arvancloud/cli/pkg/config"
+	"github.com/arvancloud/cli/pkg/options"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+var (
+	whoAmILong = `
+    Show the identity arvan cli is logged in with
+
+    Prints account email and ID, PaaS username, current zone, API endpoint and fingerprint of
+    the API key or token in use, and whether it's still valid. The key itself is never printed.`
+)
+
+const (
+	outputFormatJSON = "json"
+)
+
+// Identity describes credentials arvan cli is logged in with.
+type Identity struct {
+	Email          string `json:"email"`
+	ID             string `json:"id"`
+	PaasUsername   string `json:"paasUsername"`
+	Zone           string `json:"zone"`
+	Endpoint       string `json:"endpoint"`
+	AuthType       string `json:"authType"`
+	KeyFingerprint string `json:"keyFingerprint"`
+	Valid          bool   `json:"valid"`
+	Error          string `json:"error,omitempty"`
+}
+
+// NewCmdWhoAmI returns new cobra commad showing current identity.
+func NewCmdWhoAmI(o *options.Options) *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "whoami",
+		Short: "Show current identity",
+		Long:  whoAmILong,
+		Run: func(c *cobra.Command, args []string) {
+			o.CheckErr(validateOutputFormat(output))
+
+			o.CheckErr(requireCredentials(o))
+
+			identity, err := getIdentity(o)
+
+			if output == outputFormatJSON {
+				o.CheckErr(printJSON(o.Out, identity))
+			} else {
+				sprintIdentity(o.Out, identity)
+			}
+			o.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format. One of: json")
+
+	return cmd
+}
+
+// getIdentity collects identity of current credentials. It returns the identity collected so far
+// along with an error if credentials are not valid.
+func getIdentity(o *options.Options) (Identity, error) {
+	arvanConfig := o.Config
+	identity := Identity{
+		Zone:           getRegionFromEndpoint(arvanConfig.GetServer()),
+		Endpoint:       arvanConfig.GetServer(),
+		AuthType:       arvanConfig.GetAuthType(),
+		KeyFingerprint: keyFingerprint(arvanConfig.GetToken()),
+	}
+
+	// service account tokens are only valid for paas api
+	if arvanConfig.GetAuthType() != config.AuthTypeServiceAccount {
+		user, err := o.Client.GetUserInfo(getArvanAuthorization(arvanConfig))
+		if err != nil {
+			identity.Error = err.Error()
+			return identity, err
+		}
+		identity.Email = user["email"]
+		identity.ID = user["id"]
+	}
+
+	// #TODO do not use InsecureSkipVerify
+	o.Client.InsecureSkipTLSVerify()
+	username, httpStatusCode, err := whoAmI(o)
+	if err != nil {
+		err = whoAmIError(httpStatusCode, err)
+		identity.Error = err.Error()
+		return identity, err
+	}
+	identity.PaasUsername = username
+	identity.Valid = true
+
+	return identity, nil
+}
+
+// keyFingerprint returns a fingerprint identifying apiKey without revealing it e.g SHA256:3a7bd3e2360a3d29.
+func keyFingerprint(apiKey string) string {
+	if len(apiKey) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(apiKey))
+	return "SHA256:" + hex.EncodeToString(sum[:8])
+}
+
+// sprintIdentity displays identity in lines.
+func sprintIdentity(out io.Writer, identity Identity) {
+	w := new(tabwriter.Writer)
+	w.Init(out, 0, 8, 2, ' ', 0)
+	defer w.Flush()
+
+	valid := "yes"
+	if !identity.Valid {
+		valid = "no"
+	}
+	fmt.Fprintf(w, "Email:\t%s\n", identity.Email)
+	fmt.Fprintf(w, "ID:\t%s\n", identity.ID)
+	fmt.Fprintf(w, "PaaS Username:\t%s\n", identity.PaasUsername)
+	fmt.Fprintf(w, "Zone:\t%s\n", identity.Zone)
+	fmt.Fprintf(w, "Endpoint:\t%s\n", identity.Endpoint)
+	fmt.Fprintf(w, "Auth Type:\t%s\n", identity.AuthType)
+	fmt.Fprintf(w, "Key Fingerprint:\t%s\n", identity.KeyFingerprint)
+	fmt.Fprintf(w, "Valid:\t%s\n", valid)
+}
+
+// validateOutputFormat makes sure output is either empty for human readable output or json.
+func validateOutputFormat(output string) error {
+	if output != "" && output != outputFormatJSON {
+		return utl.Errorf(utl.KindValidation, "invalid output format %q. One of: json", output)
+	}
+	return nil
+}
+
+// printJSON writes v as indented json.
+func printJSON(out io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "%s\n", data)
+	return err
+}
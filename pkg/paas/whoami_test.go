@@ -0,0 +1,50 @@
+package paas_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/arvancloud/cli/pkg/api/apitest"
+	"github.com/arvancloud/cli/pkg/cli/clitest"
+	"github.com/arvancloud/cli/pkg/paas"
+	"github.com/arvancloud/cli/pkg/utl"
+)
+
+func TestWhoAmI(t *testing.T) {
+	h := clitest.New(t)
+
+	result := h.Run("", "whoami")
+	if result.ExitCode != 0 {
+		t.Fatalf("whoami failed:\n%s", result)
+	}
+	for _, line := range []string{"jane@example.com", "PaaS Username:    jane", "Zone:             ir-thr-at1", "Valid:            yes"} {
+		if !strings.Contains(result.Stdout, line) {
+			t.Errorf("output does not contain %q:\n%s", line, result)
+		}
+	}
+	if strings.Contains(result.Stdout, strings.TrimPrefix(apitest.DefaultApiKey, "Apikey ")) {
+		t.Errorf("api key is printed:\n%s", result)
+	}
+}
+
+func TestWhoAmIJSON(t *testing.T) {
+	h := clitest.New(t)
+	h.Server.ApiKey = "Apikey 11111111-1111-1111-1111-111111111111"
+
+	result := h.Run("", "whoami", "-o", "json")
+	if result.ExitCode != utl.AuthErrorExitCode {
+		t.Fatalf("exit code = %d, want %d:\n%s", result.ExitCode, utl.AuthErrorExitCode, result)
+	}
+
+	var identity paas.Identity
+	if err := json.Unmarshal([]byte(result.Stdout), &identity); err != nil {
+		t.Fatalf("output is not a json identity: %v\n%s", err, result)
+	}
+	if identity.Valid || len(identity.Error) == 0 {
+		t.Errorf("identity of a revoked key = %+v, want invalid with an error", identity)
+	}
+	if identity.Zone != "ir-thr-at1" || identity.AuthType != "apikey" || !strings.HasPrefix(identity.KeyFingerprint, "SHA256:") {
+		t.Errorf("identity = %+v, want zone, auth type and fingerprint of the saved key", identity)
+	}
+}
@@ -0,0 +1,86 @@
+package utl
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorKind classifies an error so callers and scripts can react to it.
+type ErrorKind string
+
+const (
+	KindUnknown     ErrorKind = "unknown"
+	KindValidation  ErrorKind = "validation"
+	KindAuth        ErrorKind = "auth"
+	KindNetwork     ErrorKind = "network"
+	KindNotFound    ErrorKind = "not-found"
+	KindServer      ErrorKind = "server"
+	KindUserAborted ErrorKind = "user-aborted"
+)
+
+// Exit codes returned by arvan cli. Keep in sync with README.md.
+const (
+	ValidationErrorExitCode  = 2
+	AuthErrorExitCode        = 3
+	NetworkErrorExitCode     = 4
+	NotFoundErrorExitCode    = 5
+	ServerErrorExitCode      = 6
+	UserAbortedErrorExitCode = 130
+)
+
+var exitCodes = map[ErrorKind]int{
+	KindUnknown:     DefaultErrorExitCode,
+	KindValidation:  ValidationErrorExitCode,
+	KindAuth:        AuthErrorExitCode,
+	KindNetwork:     NetworkErrorExitCode,
+	KindNotFound:    NotFoundErrorExitCode,
+	KindServer:      ServerErrorExitCode,
+	KindUserAborted: UserAbortedErrorExitCode,
+}
+
+// Error is an error annotated with its kind.
+type Error struct {
+	Kind ErrorKind
+	Err  error
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return string(e.Kind)
+	}
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewError annotates err with kind. It returns nil if err is nil.
+func NewError(kind ErrorKind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// Errorf formats according to a format specifier and returns an error of the given kind.
+func Errorf(kind ErrorKind, format string, a ...interface{}) error {
+	return &Error{Kind: kind, Err: fmt.Errorf(format, a...)}
+}
+
+// KindOf returns the kind of err, or KindUnknown if err is not annotated.
+func KindOf(err error) ErrorKind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindUnknown
+}
+
+// ExitCode returns the process exit code matching the kind of err.
+func ExitCode(err error) int {
+	if code, ok := exitCodes[KindOf(err)]; ok {
+		return code
+	}
+	return DefaultErrorExitCode
+}
@@ -5,6 +5,7 @@ import (
 	"archive/zip"
 	"bufio"
 	"compress/gzip"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -16,12 +17,28 @@ import (
 
 const (
 	DefaultErrorExitCode = 1
+
+	ErrorFormatText = "text"
+	ErrorFormatJSON = "json"
 )
 
-// #TODO improve check error to print better
+var fatalErrHandler = fatal
+
+// BehaviorOnFatal allows you to override the default behavior when a fatal
+// error occurs, which is to call os.Exit(code). You can pass 'panic' as a function
+// here if you prefer the panic() over os.Exit(1).
+func BehaviorOnFatal(f func(string, int)) {
+	fatalErrHandler = f
+}
+
+// DefaultBehaviorOnFatal allows you to undo any previous override.  Useful in
+// tests.
+func DefaultBehaviorOnFatal() {
+	fatalErrHandler = fatal
+}
 
 // fatal prints the message (if provided) and then exits.
-func fatalErrHandler(msg string, code int) {
+func fatal(msg string, code int) {
 	if len(msg) > 0 {
 		// add newline if needed
 		if !strings.HasSuffix(msg, "\n") {
@@ -32,8 +49,8 @@ func fatalErrHandler(msg string, code int) {
 	os.Exit(code)
 }
 
-// CheckErr prints a user friendly error to STDERR and exits with a non-zero
-// exit code. Unrecognized errors will be printed with an "error: " prefix.
+// CheckErr prints a user friendly error to STDERR and exits with the exit code
+// matching the kind of the error. See ExitCode.
 func CheckErr(err error) {
 	checkErr(err, fatalErrHandler)
 }
@@ -43,15 +60,41 @@ func checkErr(err error, handleErr func(string, int)) {
 	if err == nil {
 		return
 	}
-	fmt.Println(err)
-	handleErr("", DefaultErrorExitCode)
+	handleErr(FormatErr(err, ErrorFormatText), ExitCode(err))
+}
+
+// ValidateErrorFormat returns a validation error if format is not one of the supported error formats.
+func ValidateErrorFormat(format string) error {
+	if format != ErrorFormatText && format != ErrorFormatJSON {
+		return Errorf(KindValidation, "invalid error format %q. One of: text|json", format)
+	}
+	return nil
+}
+
+// FormatErr formats err according to format. Formats other than ErrorFormatJSON are printed as text.
+func FormatErr(err error, format string) string {
+	if format != ErrorFormatJSON {
+		return err.Error()
+	}
+	data, jsonErr := json.Marshal(map[string]interface{}{
+		"error": map[string]interface{}{
+			"kind":     KindOf(err),
+			"message":  err.Error(),
+			"exitCode": ExitCode(err),
+		},
+	})
+	if jsonErr != nil {
+		return err.Error()
+	}
+	return string(data)
 }
 
 // ReadInput prints explain and repeat printing inputExplain to out and reads a string from in.
 //
 //	If input is empty and defaultVal is set returns default value
 //	If defaultVal is not set, tries to validate input using validate
-func ReadInput(inputExplain, defaultVal string, out io.Writer, in io.Reader, validate func(string) (bool, error)) string {
+//	If in is closed before a valid input is read, returns a user-aborted error
+func ReadInput(inputExplain, defaultVal string, out io.Writer, in io.Reader, validate func(string) (bool, error)) (string, error) {
 	reader := bufio.NewReader(in)
 	for {
 		_, err := fmt.Fprint(out, inputExplain)
@@ -59,6 +102,13 @@ func ReadInput(inputExplain, defaultVal string, out io.Writer, in io.Reader, val
 			log.Println(err)
 		}
 		i, err := reader.ReadString('\n')
+		if err == io.EOF && len(i) > 0 {
+			err = nil
+		}
+		if err == io.EOF {
+			fmt.Fprintln(out)
+			return "", Errorf(KindUserAborted, "no input provided")
+		}
 		if err != nil {
 			_, err := fmt.Fprintf(out, "Error: %s\n", err.Error())
 			if err != nil {
@@ -67,11 +117,11 @@ func ReadInput(inputExplain, defaultVal string, out io.Writer, in io.Reader, val
 		} else {
 			i = strings.TrimSpace(i)
 			if len(i) == 0 && len(defaultVal) > 0 {
-				return defaultVal
+				return defaultVal, nil
 			}
 			valid, err := validate(i)
 			if valid {
-				return i
+				return i, nil
 			}
 			_, err = fmt.Fprintf(out, "Error: %s\n", err.Error())
 			if err != nil {
@@ -0,0 +1,51 @@
+package utl
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReadInput(t *testing.T) {
+	validate := func(input string) (bool, error) {
+		if input != "y" && input != "N" {
+			return false, errors.New("enter y or N")
+		}
+		return true, nil
+	}
+
+	tests := []struct {
+		name       string
+		input      string
+		defaultVal string
+		expected   string
+		kind       ErrorKind
+	}{
+		{name: "valid", input: "y\n", expected: "y"},
+		{name: "default", input: "\n", defaultVal: "N", expected: "N"},
+		{name: "retry after invalid", input: "maybe\nN\n", expected: "N"},
+		{name: "last line without newline", input: "y", expected: "y"},
+		{name: "no input", input: "", defaultVal: "N", kind: KindUserAborted},
+		{name: "closed after invalid", input: "maybe\n", kind: KindUserAborted},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			actual, err := ReadInput("Continue? ", test.defaultVal, out, strings.NewReader(test.input), validate)
+			if len(test.kind) > 0 {
+				if KindOf(err) != test.kind {
+					t.Errorf("error = %v, want %s error", err, test.kind)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != test.expected {
+				t.Errorf("input = %q, want %q", actual, test.expected)
+			}
+		})
+	}
+}